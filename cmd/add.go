@@ -2,9 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/vkhangstack/taskman/internal/dates"
+	"github.com/vkhangstack/taskman/internal/output"
+	"github.com/vkhangstack/taskman/internal/recur"
 	"github.com/vkhangstack/taskman/internal/task"
 	"github.com/vkhangstack/taskman/internal/ui"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -16,13 +20,23 @@ var addCmd = &cobra.Command{
 	Args:  cobra.MinimumNArgs(1),
 	Example: `  taskman add "Buy groceries"
   taskman add "Call dentist" --priority high
-  taskman add "Review code" -p medium --tags work,urgent`,
+  taskman add "Review code" -p medium --tags work,urgent
+  taskman add "Send invoice" --due eom
+  taskman add "Prepare demo" --due fri
+  taskman add "Weekly report" --recur weekly:fri
+  taskman add "Send invoices" --recur monthly:15
+  taskman add "Write tests" --parent 12
+  taskman add "Fix login" --project work.backend.api`,
 	RunE: addTask,
 }
 
 var (
 	priority string
 	tags     []string
+	due      string
+	recurs   string
+	parentID int
+	project  string
 )
 
 func init() {
@@ -30,6 +44,10 @@ func init() {
 
 	addCmd.Flags().StringVarP(&priority, "priority", "p", "medium", "Task priority (low, medium, high)")
 	addCmd.Flags().StringSliceVarP(&tags, "tags", "t", []string{}, "Tags for the task")
+	addCmd.Flags().StringVarP(&recurs, "recur", "r", "", "Recurrence rule (daily, weekly:mon,thu, monthly:15, every:2w or an RRULE)")
+	addCmd.Flags().StringVarP(&due, "due", "d", "", "Due date (2026-01-31, tomorrow, fri, +3d, eow, eom)")
+	addCmd.Flags().IntVar(&parentID, "parent", 0, "ID of the task this is a subtask of")
+	addCmd.Flags().StringVarP(&project, "project", "P", "", "Project, with dots for subprojects (work.backend.api)")
 }
 
 func addTask(cmd *cobra.Command, args []string) error {
@@ -40,7 +58,7 @@ func addTask(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid priority: %s. Valid priorities are: low, medium, high", priority)
 	}
 
-	store, err := task.NewFileStore()
+	store, err := openStore()
 	if err != nil {
 		return fmt.Errorf("failed to initialize task store: %w", err)
 	}
@@ -59,6 +77,43 @@ func addTask(cmd *cobra.Command, args []string) error {
 		Priority:    priority,
 		Tags:        tags,
 		Status:      task.StatusTodo,
+		ParentID:    parentID,
+		Project:     strings.TrimSpace(project),
+	}
+
+	if err := task.ValidateProject(newTask.Project); err != nil {
+		return err
+	}
+
+	if parentID != 0 {
+		if _, err := store.GetByID(parentID); err != nil {
+			return fmt.Errorf("failed to retrieve parent task with ID %d: %w", parentID, err)
+		}
+	}
+
+	if due != "" {
+		dueDate, err := dates.Parse(due, time.Now())
+		if err != nil {
+			return err
+		}
+		newTask.Due = &dueDate
+	}
+
+	if recurs != "" {
+		rule, err := recur.Parse(recurs)
+		if err != nil {
+			return err
+		}
+		newTask.Recur = recurs
+
+		// Without an explicit due date the series starts at its first occurrence
+		if newTask.Due == nil {
+			first := dates.EndOfDay(time.Now())
+			if !rule.Matches(first) {
+				first, _ = rule.Next(first)
+			}
+			newTask.Due = &first
+		}
 	}
 
 	id, err := store.Add(newTask)
@@ -66,6 +121,17 @@ func addTask(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to add task: %w", err)
 	}
 
+	if structuredOutput() {
+		res := output.NewResult("add")
+		res.CreatedID = id
+		added, err := store.GetByID(id)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve task with ID %d: %w", id, err)
+		}
+		res.AddTask(added)
+		return writeResult(res)
+	}
+
 	ui.PrintSuccess(fmt.Sprintf("Task added successfully! (ID: %d)", id))
 
 	// Print the added task
@@ -73,6 +139,18 @@ func addTask(cmd *cobra.Command, args []string) error {
 	if len(tags) > 0 {
 		fmt.Printf("  Tags: %s\n", ui.FormatTags(tags))
 	}
+	if newTask.Due != nil {
+		fmt.Printf("  Due: %s\n", ui.FormatDue(newTask))
+	}
+	if newTask.IsRecurring() {
+		fmt.Printf("  Recurs: %s\n", newTask.Recur)
+	}
+	if newTask.Project != "" {
+		fmt.Printf("  Project: %s\n", newTask.Project)
+	}
+	if newTask.ParentID != 0 {
+		fmt.Printf("  Parent: %s\n", ui.FormatID(newTask.ParentID))
+	}
 
 	return nil
 }
@@ -3,47 +3,88 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/output"
 	"github.com/vkhangstack/taskman/internal/task"
 	"github.com/vkhangstack/taskman/internal/ui"
-	"strconv"
 )
 
 var completeCmd = &cobra.Command{
-	Use:   "complete [task ID]",
-	Short: "Complete a task",
-	Long:  `Mark a task as completed by providing its ID. This will update the task status to 'completed'.`,
-	Args:  cobra.ExactArgs(1),
-	Example: `taskman complete 1
-  	taskman complete 1 2 3
-	taskman done 5 --force`,
+	Use:     "complete [task ID... | filter]",
+	Aliases: []string{"done"},
+	Short:   "Complete a task",
+	Long: `Mark tasks as completed by providing their IDs or a filter expression. This will update the task status to 'completed'.
+Completing a task with open subtasks prints a warning; use --recursive to complete the subtasks too.
+See 'taskman list --help' for the filter syntax.`,
+	Args: cobra.MinimumNArgs(1),
+	Example: `  taskman complete 1
+  taskman complete 1 2 3
+  taskman done 5
+  taskman complete '+standup and due.before:today'
+  taskman complete 12 --recursive`,
 	RunE: completeTask,
 }
 
+var completeRecursive bool
+
 func init() {
 	rootCmd.AddCommand(completeCmd)
+	completeCmd.Flags().BoolVarP(&completeRecursive, "recursive", "r", false, "Also complete all open subtasks")
 }
 func completeTask(cmd *cobra.Command, args []string) error {
-	// Convert args to integers
-	var ids []int
-	for _, arg := range args {
-		id, err := strconv.Atoi(arg)
-		if err != nil {
-			return fmt.Errorf("invalid task ID: %s", arg)
-		}
-		ids = append(ids, id)
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
 	}
 
-	store, err := task.NewFileStore()
+	ids, err := resolveTargets(store, args)
 	if err != nil {
-		return fmt.Errorf("failed to initialize task store: %w", err)
+		return err
 	}
 
-	for _, id := range ids {
+	tasks, err := store.GetAll()
+	if err != nil {
+		return err
+	}
+	children := task.ChildrenIndex(tasks)
+
+	res := output.NewResult("complete")
+	completed := make(map[int]bool)
+	complete := func(id int) {
+		if completed[id] {
+			return
+		}
+		completed[id] = true
 		if err := store.Complete(id); err != nil {
-			return fmt.Errorf("failed to complete task with ID %d: %w", id, err)
+			reportError(res, id, fmt.Errorf("failed to complete task with ID %d: %w", id, err))
+			return
+		}
+		if t, err := store.GetByID(id); err == nil {
+			res.AddTask(t)
 		}
 	}
 
-	ui.PrintSuccess("Tasks completed successfully!")
-	return nil
+	for _, id := range ids {
+		var open []*task.Task
+		for _, d := range task.Descendants(children, id) {
+			if d.IsOpen() {
+				open = append(open, d)
+			}
+		}
+
+		if len(open) > 0 && !completeRecursive {
+			ui.PrintWarning(fmt.Sprintf("Task %d has %d open subtask(s); use --recursive to complete them too", id, len(open)))
+		}
+		if completeRecursive {
+			// Deepest subtasks first
+			for i := len(open) - 1; i >= 0; i-- {
+				complete(open[i].ID)
+			}
+		}
+		complete(id)
+	}
+
+	if !structuredOutput() && len(res.Tasks) > 0 {
+		ui.PrintSuccess("Tasks completed successfully!")
+	}
+	return finishResult(cmd, res)
 }
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/vkhangstack/taskman/internal/query"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Show or switch the active context",
+	Long: `A context is a filter that applies to every command until it is switched off:
+list, next, watch, reports, timesheet and projects only show matching tasks,
+export and report render only write matching tasks, and filters given to
+complete, delete, modify and process only select matching tasks. Task IDs given
+explicitly are not affected. These commands print the active context when it
+narrows their tasks. The REST API and the JSON-RPC mode ignore contexts.
+
+Contexts are defined under contexts: in ~/.taskman.yaml:
+
+  contexts:
+    work: project:work or +work
+    home: project:home
+
+Use --context NAME to use another context for one command, or --context none
+to ignore the active one.`,
+	Example: `  taskman context set work
+  taskman context
+  taskman list --context none
+  taskman context none`,
+	Args: cobra.NoArgs,
+	RunE: showContexts,
+}
+
+var contextSetCmd = &cobra.Command{
+	Use:     "set [name]",
+	Short:   "Switch to a context",
+	Example: `  taskman context set work`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    setContext,
+}
+
+var contextNoneCmd = &cobra.Command{
+	Use:   "none",
+	Short: "Switch the active context off",
+	Args:  cobra.NoArgs,
+	RunE:  clearContext,
+}
+
+var contextFlag string
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+	contextCmd.AddCommand(contextSetCmd)
+	contextCmd.AddCommand(contextNoneCmd)
+
+	rootCmd.PersistentFlags().StringVar(&contextFlag, "context", "", "context to use for this command instead of the active one, or none")
+}
+
+func showContexts(cmd *cobra.Command, args []string) error {
+	name, filter, err := activeContext()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		ui.PrintInfo("No context is active.")
+	} else {
+		ui.PrintInfo(fmt.Sprintf("Active context: %s (%s)", name, filter))
+	}
+
+	contexts := viper.GetStringMapString("contexts")
+	if len(contexts) == 0 {
+		ui.PrintInfo("No contexts defined. Add them under contexts: in ~/.taskman.yaml.")
+		return nil
+	}
+
+	names := make([]string, 0, len(contexts))
+	for n := range contexts {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	fmt.Println()
+	for _, n := range names {
+		marker := " "
+		if n == name {
+			marker = "*"
+		}
+		fmt.Printf("%s %-16s %s\n", marker, n, ui.CyanText.Sprint(contexts[n]))
+	}
+	return nil
+}
+
+func setContext(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+	if name == "none" {
+		return clearContext(cmd, nil)
+	}
+	filter, err := contextFilter(name)
+	if err != nil {
+		return err
+	}
+	if _, err := query.Parse(filter, time.Now()); err != nil {
+		return fmt.Errorf("invalid filter of context %s: %w", name, err)
+	}
+
+	path, err := contextPath()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(name+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to save context: %w", err)
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Switched to context %s (%s)", name, filter))
+	return nil
+}
+
+func clearContext(cmd *cobra.Command, args []string) error {
+	path, err := contextPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to clear context: %w", err)
+	}
+
+	ui.PrintSuccess("Context switched off")
+	return nil
+}
+
+// contextPath returns the file that stores the name of the active context
+func contextPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".taskman", "context"), nil
+}
+
+// activeContext returns the name and filter of the context selected with
+// --context, or else of the one saved by 'context set'. The name is empty when
+// no context applies.
+func activeContext() (string, string, error) {
+	name := strings.ToLower(strings.TrimSpace(contextFlag))
+	if name == "" {
+		path, err := contextPath()
+		if err != nil {
+			return "", "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", "", fmt.Errorf("failed to read context: %w", err)
+		}
+		name = strings.TrimSpace(string(data))
+	}
+	if name == "" || name == "none" {
+		return "", "", nil
+	}
+
+	filter, err := contextFilter(name)
+	if err != nil {
+		return "", "", err
+	}
+	return name, filter, nil
+}
+
+// contextFilter returns the filter of a context defined in the config
+func contextFilter(name string) (string, error) {
+	filter, ok := viper.GetStringMapString("contexts")[name]
+	if !ok {
+		return "", fmt.Errorf("context %s is not defined under contexts: in the config", name)
+	}
+	return filter, nil
+}
+
+// showContext tells which context the tasks shown were filtered by
+func showContext() {
+	if name, filter, err := activeContext(); err == nil && name != "" {
+		ui.PrintInfo(fmt.Sprintf("Context: %s (%s)", name, filter))
+	}
+}
@@ -3,84 +3,111 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/output"
 	"github.com/vkhangstack/taskman/internal/task"
 	"github.com/vkhangstack/taskman/internal/ui"
-	"strconv"
 )
 
 var deleteCmd = &cobra.Command{
-	Use:   "delete [task ID]",
-	Short: "Delete a task",
-	Long:  `Delete a task by providing its ID. This will remove the task from your task list permanently.`,
-	Args:  cobra.MinimumNArgs(1),
+	Use:     "delete [task ID... | filter]",
+	Aliases: []string{"del", "remove"},
+	Short:   "Delete a task",
+	Long: `Delete tasks by providing their IDs or a filter expression. This will remove the tasks from your task list.
+Deleted tasks can be restored with 'taskman undo'. See 'taskman list --help' for the filter syntax.
+
+Subtasks of a deleted task are kept and move up to its parent (--orphans promote),
+or are deleted along with it (--orphans delete).`,
+	Args: cobra.MinimumNArgs(1),
 	Example: `  taskman delete 1
   taskman del 2
-  taskman remove 3`,
+  taskman remove 3
+  taskman delete 'status:completed and completed.before:-30d'
+  taskman delete 12 --orphans delete`,
 	RunE: deleteTasks,
 }
-var forceDelete bool
+var (
+	forceDelete   bool
+	deleteOrphans string
+)
+
+const (
+	orphansPromote = "promote"
+	orphansDelete  = "delete"
+)
 
 func init() {
 	rootCmd.AddCommand(deleteCmd)
 	deleteCmd.Flags().BoolVarP(&forceDelete, "force", "f", false, "Force delete the task without confirmation")
+	deleteCmd.Flags().StringVar(&deleteOrphans, "orphans", orphansPromote, "What happens to subtasks: promote (move up to the parent) or delete")
 }
 
 func deleteTasks(cmd *cobra.Command, args []string) error {
-	store, err := task.NewFileStore()
+	if deleteOrphans != orphansPromote && deleteOrphans != orphansDelete {
+		return fmt.Errorf("invalid --orphans: %s. Valid values are: promote, delete", deleteOrphans)
+	}
+	if structuredOutput() && !forceDelete {
+		// A confirmation prompt would mix with the result and hang scripts
+		return fmt.Errorf("delete with --output needs --force, as it cannot ask for confirmation")
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return err
+	}
+	ids, err := resolveTargets(store, args)
 	if err != nil {
 		return err
 	}
-	var toDelete []int
-	var errors []error
 
-	for _, arg := range args {
-		id, err := strconv.Atoi(arg)
-		if err != nil {
-			return fmt.Errorf("invalid task ID: %s", arg)
-			continue
-		}
+	res := output.NewResult("delete")
+	var toDelete []*task.Task
 
+	for _, id := range ids {
 		// Check if the task exists
-		_, err = store.GetByID(id)
+		t, err := store.GetByID(id)
 		if err != nil {
-			errors = append(errors, fmt.Errorf("failed to retrieve task with ID %d: %w", id, err))
+			reportError(res, id, fmt.Errorf("failed to retrieve task with ID %d: %w", id, err))
 			continue
 		}
-		toDelete = append(toDelete, id)
+		toDelete = append(toDelete, t)
 	}
-	if len(toDelete) == 0 {
-		for _, err := range errors {
-			ui.PrintError(err.Error())
-		}
-		if len(errors) <= 0 {
-			return nil
+
+	if deleteOrphans == orphansDelete {
+		if toDelete, err = withSubtasks(store, toDelete); err != nil {
+			return err
 		}
 	}
 
 	if !forceDelete && len(toDelete) > 0 {
 		ui.PrintWarning("You are about to delete the following tasks:")
-		for _, id := range toDelete {
-			ui.PrintInfo(fmt.Sprintf("Task ID: %d", id))
+		for _, t := range toDelete {
+			ui.PrintInfo(fmt.Sprintf("Task ID: %d", t.ID))
 		}
-		ui.PrintWarning("This action cannot be undone. Are you sure you want to proceed? (yes/no)")
+		ui.PrintWarning("Are you sure you want to proceed? (yes/no)")
 		var response string
 		fmt.Scanln(&response)
 		if response != "y" && response != "Y" && response != "yes" && response != "Yes" {
 			ui.PrintInfo("Deletion cancelled.")
-			return nil
+			return finishResult(cmd, res)
 		}
 	}
 	// delete tasks
 	var deleted []int
 	var deleteErrors []string
 
-	for _, id := range toDelete {
-		if err := store.Delete(id); err != nil {
-			deleteErrors = append(deleteErrors, fmt.Sprintf("failed to delete task with ID %d: %v", id, err))
+	for _, t := range toDelete {
+		if err := store.Delete(t.ID); err != nil {
+			err = fmt.Errorf("failed to delete task with ID %d: %w", t.ID, err)
+			res.AddError(t.ID, err)
+			deleteErrors = append(deleteErrors, err.Error())
 		} else {
-			deleted = append(deleted, id)
+			res.AddTask(t)
+			deleted = append(deleted, t.ID)
 		}
 	}
+	if structuredOutput() {
+		return finishResult(cmd, res)
+	}
 	// Print results
 	if len(deleted) > 0 {
 		if len(deleted) == 1 {
@@ -98,5 +125,29 @@ func deleteTasks(cmd *cobra.Command, args []string) error {
 			ui.PrintError(err)
 		}
 	}
-	return nil
+	return finishResult(cmd, res)
+}
+
+// withSubtasks adds the subtasks of the given tasks, at any depth, after them
+func withSubtasks(store task.Store, tasks []*task.Task) ([]*task.Task, error) {
+	all, err := store.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	children := task.ChildrenIndex(all)
+
+	seen := make(map[int]bool)
+	for _, t := range tasks {
+		seen[t.ID] = true
+	}
+	result := tasks
+	for _, t := range tasks {
+		for _, d := range task.Descendants(children, t.ID) {
+			if !seen[d.ID] {
+				seen[d.ID] = true
+				result = append(result, d)
+			}
+		}
+	}
+	return result, nil
 }
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var dependCmd = &cobra.Command{
+	Use:   "depend [task ID] --on [task ID...]",
+	Short: "Make a task depend on other tasks",
+	Long: `Make a task depend on other tasks. A task with unfinished dependencies is blocked:
+it matches the +BLOCKED filter and cannot be started with 'taskman process'.
+Dependencies that would form a cycle are rejected.`,
+	Example: `  taskman depend 3 --on 1
+  taskman depend 5 --on 2,4
+  taskman list +BLOCKED`,
+	Args: cobra.ExactArgs(1),
+	RunE: addDependencies,
+}
+
+var undependCmd = &cobra.Command{
+	Use:   "undepend [task ID] [--on task ID...]",
+	Short: "Remove dependencies from a task",
+	Long:  `Remove dependencies from a task. Without --on all of its dependencies are removed.`,
+	Example: `  taskman undepend 3 --on 1
+  taskman undepend 5`,
+	Args: cobra.ExactArgs(1),
+	RunE: removeDependencies,
+}
+
+var (
+	dependOn   []int
+	undependOn []int
+)
+
+func init() {
+	rootCmd.AddCommand(dependCmd)
+	rootCmd.AddCommand(undependCmd)
+
+	dependCmd.Flags().IntSliceVar(&dependOn, "on", []int{}, "IDs of the tasks to depend on")
+	dependCmd.MarkFlagRequired("on")
+	undependCmd.Flags().IntSliceVar(&undependOn, "on", []int{}, "IDs of the dependencies to remove (default all)")
+}
+
+func addDependencies(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid task ID: %s", args[0])
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	t, err := store.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve task with ID %d: %w", id, err)
+	}
+	for _, dep := range dependOn {
+		if _, err := store.GetByID(dep); err != nil {
+			return fmt.Errorf("failed to retrieve task with ID %d: %w", dep, err)
+		}
+		t.AddDependency(dep)
+	}
+
+	if err := store.Update(t); err != nil {
+		return fmt.Errorf("failed to add dependencies to task with ID %d: %w", id, err)
+	}
+
+	if structuredOutput() {
+		return writeTaskResult("depend", store, id)
+	}
+	ui.PrintSuccess(fmt.Sprintf("Task %d now depends on %s", id, formatIDs(t.DependsOn)))
+	return nil
+}
+
+func removeDependencies(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid task ID: %s", args[0])
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	t, err := store.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve task with ID %d: %w", id, err)
+	}
+
+	if len(undependOn) == 0 {
+		t.DependsOn = nil
+	}
+	for _, dep := range undependOn {
+		if !t.DependsOnTask(dep) {
+			ui.PrintWarning(fmt.Sprintf("Task %d does not depend on task %d", id, dep))
+			continue
+		}
+		t.RemoveDependency(dep)
+	}
+
+	if err := store.Update(t); err != nil {
+		return fmt.Errorf("failed to remove dependencies from task with ID %d: %w", id, err)
+	}
+
+	if structuredOutput() {
+		return writeTaskResult("undepend", store, id)
+	}
+	if len(t.DependsOn) == 0 {
+		ui.PrintSuccess(fmt.Sprintf("Task %d has no dependencies", id))
+	} else {
+		ui.PrintSuccess(fmt.Sprintf("Task %d now depends on %s", id, formatIDs(t.DependsOn)))
+	}
+	return nil
+}
+
+// formatIDs formats task IDs as "#1, #2"
+func formatIDs(ids []int) string {
+	s := ""
+	for i, id := range ids {
+		if i > 0 {
+			s += ", "
+		}
+		s += ui.FormatID(id)
+	}
+	return s
+}
+
+// blockedBy returns the unfinished dependencies of a task, or nil if it can be started
+func blockedBy(store task.Store, t *task.Task) ([]int, error) {
+	if len(t.DependsOn) == 0 {
+		return nil, nil
+	}
+	tasks, err := store.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return t.Blockers(task.IndexTasks(tasks)), nil
+}
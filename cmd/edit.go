@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/dates"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/ui"
+	"gopkg.in/yaml.v3"
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit [task ID]",
+	Short: "Edit a task in your editor",
+	Long: `Open a task as YAML in $EDITOR (or $VISUAL). When the editor exits, the YAML is
+validated and the task is updated. If the YAML is invalid you can re-open the editor
+to fix it.`,
+	Example: `  taskman edit 3
+  EDITOR=nano taskman edit 3`,
+	Args: cobra.ExactArgs(1),
+	RunE: editTask,
+}
+
+// editableTask is the YAML document shown in the editor
+type editableTask struct {
+	Description string   `yaml:"description"`
+	Status      string   `yaml:"status"`
+	Priority    string   `yaml:"priority"`
+	Project     string   `yaml:"project"`
+	Tags        []string `yaml:"tags"`
+	Due         string   `yaml:"due"`
+	Recur       string   `yaml:"recur"`
+}
+
+const editHeader = `# Edit the task below, then save and close the editor.
+# status: todo, pending, in_progress, completed, archived, deleted
+# priority: low, medium, high
+# project: dotted name such as work.backend (empty for none)
+# due: 2026-01-31, 2026-01-31 15:04, tomorrow, fri, +3d, ... (empty for none)
+# recur: daily, weekly:mon,thu, monthly:15, every:2w or an RRULE (empty for none)
+`
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+}
+
+func editTask(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid task ID: %s", args[0])
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	t, err := store.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve task with ID %d: %w", id, err)
+	}
+
+	doc := editableTask{
+		Description: t.Description,
+		Status:      t.Status,
+		Priority:    t.Priority,
+		Project:     t.Project,
+		Tags:        t.Tags,
+		Recur:       t.Recur,
+	}
+	if t.Due != nil {
+		doc.Due = formatEditableDue(*t.Due)
+	}
+
+	original, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+	content := append([]byte(editHeader), original...)
+
+	complete := false
+	for {
+		edited, err := runEditor(content)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(edited, content) {
+			ui.PrintInfo("No changes made.")
+			if structuredOutput() {
+				return writeTaskResult("edit", store, id)
+			}
+			return nil
+		}
+
+		complete, err = applyEditedTask(t, edited)
+		if err == nil {
+			break
+		}
+
+		if structuredOutput() {
+			// Asking to re-open the editor would mix with the result
+			return err
+		}
+		ui.PrintError(err.Error())
+		ui.PrintWarning("Re-open the editor to fix it? (yes/no)")
+		var response string
+		fmt.Scanln(&response)
+		if response != "y" && response != "Y" && response != "yes" && response != "Yes" {
+			ui.PrintInfo("Edit cancelled.")
+			return nil
+		}
+		content = edited
+	}
+
+	if err := store.Update(t); err != nil {
+		return fmt.Errorf("failed to update task with ID %d: %w", id, err)
+	}
+	if complete {
+		if err := store.Complete(id); err != nil {
+			return fmt.Errorf("failed to complete task with ID %d: %w", id, err)
+		}
+	}
+
+	if structuredOutput() {
+		return writeTaskResult("edit", store, id)
+	}
+	ui.PrintSuccess(fmt.Sprintf("Task %d updated successfully!", id))
+	return nil
+}
+
+// applyEditedTask parses and validates the edited YAML and copies it into t.
+// It reports whether the task was moved to completed, which is left to the
+// store so recurring tasks spawn their next occurrence.
+func applyEditedTask(t *task.Task, edited []byte) (bool, error) {
+	var doc editableTask
+	dec := yaml.NewDecoder(bytes.NewReader(edited))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil {
+		return false, fmt.Errorf("invalid YAML: %w", err)
+	}
+
+	var due *time.Time
+	switch edited := strings.TrimSpace(doc.Due); {
+	case edited == "":
+	case t.Due != nil && edited == formatEditableDue(*t.Due):
+		// Keep the seconds and location the editor does not show
+		due = t.Due
+	default:
+		d, err := dates.Parse(edited, time.Now())
+		if err != nil {
+			return false, err
+		}
+		due = &d
+	}
+
+	updated := *t
+	updated.Description = strings.TrimSpace(doc.Description)
+	updated.Priority = doc.Priority
+	updated.Project = strings.TrimSpace(doc.Project)
+	updated.Tags = nil
+	for _, tag := range doc.Tags {
+		updated.Tags = append(updated.Tags, strings.TrimSpace(tag))
+	}
+	updated.Due = due
+	updated.Recur = strings.TrimSpace(doc.Recur)
+	complete := doc.Status == task.StatusCompleted && !t.IsCompleted()
+	if doc.Status != t.Status && !complete {
+		updated.MarkStatus(doc.Status)
+	}
+
+	if err := validateTask(&updated); err != nil {
+		return false, err
+	}
+
+	*t = updated
+	return complete, nil
+}
+
+// formatEditableDue formats a due date for the editor, leaving out the time
+// of day for dates that are due at the end of the day
+func formatEditableDue(due time.Time) string {
+	if h, m, sec := due.Clock(); h == 23 && m == 59 && sec == 59 {
+		return due.Format("2006-01-02")
+	}
+	return due.Format("2006-01-02 15:04")
+}
+
+// runEditor writes content to a temporary file, opens it in the user's editor
+// and returns the edited content
+func runEditor(content []byte) ([]byte, error) {
+	f, err := os.CreateTemp("", "taskman-*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	editor := strings.Fields(editorCommand())
+	c := exec.Command(editor[0], append(editor[1:], f.Name())...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return nil, fmt.Errorf("editor %s failed: %w", editor[0], err)
+	}
+
+	return os.ReadFile(f.Name())
+}
+
+// editorCommand returns $VISUAL or $EDITOR, falling back to a platform default
+func editorCommand() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/ical"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/taskwarrior"
+	"github.com/vkhangstack/taskman/internal/todotxt"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [filter]",
+	Short: "Export tasks for other task managers",
+	Long: `Write tasks to stdout in the format of another task manager, oldest first.
+Give a filter to only export matching tasks.
+
+Formats:
+  todotxt       one todo.txt line per task: priority as (A)/(B)/(C), the
+                project as +project, tags as @context, due:YYYY-MM-DD and x
+                with the completion date for completed tasks. Deleted tasks
+                are left out.
+  taskwarrior   a JSON array for 'task import', with uuid, entry, modified,
+                end, status, priority H/M/L, project, tags, due, depends and
+                annotations. Tasks keep the UUID they were imported with;
+                other tasks get a UUID that stays the same between exports.
+  ics           an iCalendar file with a VTODO per task, for calendar clients:
+                UID, SUMMARY, PRIORITY 1/5/9, CATEGORIES from the tags, STATUS,
+                DUE, COMPLETED, CREATED and LAST-MODIFIED, with the project and
+                the statuses STATUS has no value for in X-TASKMAN- properties.
+                The UID is the UUID of the taskwarrior format.`,
+	Example: `  taskman export --format todotxt > todo.txt
+  taskman export --format todotxt +OPEN project:work
+  taskman export --format taskwarrior | task import
+  taskman export --format ics +OPEN > tasks.ics`,
+	RunE: exportTasks,
+}
+
+var exportFormat string
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(&exportFormat, "format", "", "Export format: todotxt, taskwarrior, ics")
+	exportCmd.MarkFlagRequired("format")
+}
+
+func exportTasks(cmd *cobra.Command, args []string) error {
+	switch exportFormat {
+	case "todotxt", "taskwarrior", "ics":
+	default:
+		return fmt.Errorf("invalid export format: %s. Valid formats are: todotxt, taskwarrior, ics", exportFormat)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	q, err := parseFilter(args)
+	if err != nil {
+		return err
+	}
+	// stdout carries the export, so the context goes to stderr
+	ui.Messages = os.Stderr
+	showContext()
+	all, err := store.GetAll()
+	if err != nil {
+		return err
+	}
+
+	tasks := q.Filter(all)
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	switch exportFormat {
+	case "taskwarrior":
+		index := task.IndexTasks(all)
+		uuidOf := func(id int) string {
+			if t, ok := index[id]; ok {
+				return t.SyncUUID()
+			}
+			return ""
+		}
+
+		exported := make([]taskwarrior.Task, 0, len(tasks))
+		for _, t := range tasks {
+			exported = append(exported, taskwarrior.FromTask(t, uuidOf))
+		}
+		return taskwarrior.Write(os.Stdout, exported)
+	case "ics":
+		return ical.Write(os.Stdout, tasks)
+	default:
+		var open []*task.Task
+		for _, t := range tasks {
+			if t.Status != task.StatusDeleted {
+				open = append(open, t)
+			}
+		}
+		return todotxt.Write(os.Stdout, open)
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vkhangstack/taskman/internal/query"
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// parseFilter parses the command arguments as one filter expression, which is
+// combined with the filter of the active context
+func parseFilter(args []string) (*query.Query, error) {
+	return parseFilters(strings.Join(args, " "))
+}
+
+// parseFilters parses filter expressions that tasks have to match all of,
+// together with the filter of the active context
+func parseFilters(filters ...string) (*query.Query, error) {
+	_, active, err := activeContext()
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []string
+	for _, f := range append([]string{active}, filters...) {
+		if strings.TrimSpace(f) != "" {
+			parts = append(parts, "("+f+")")
+		}
+	}
+	if len(parts) == 1 {
+		// Keep a single filter as written so that messages quote it unchanged
+		parts[0] = strings.TrimSuffix(strings.TrimPrefix(parts[0], "("), ")")
+	}
+	return query.Parse(strings.Join(parts, " and "), time.Now())
+}
+
+// resolveTargets turns the arguments of a bulk command into task IDs. When
+// every argument is a number they are taken as IDs; otherwise the arguments
+// form a filter expression and the IDs of all matching tasks are returned.
+func resolveTargets(store task.Store, args []string) ([]int, error) {
+	var ids []int
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			ids = nil
+			break
+		}
+		ids = append(ids, id)
+	}
+	if ids != nil {
+		return ids, nil
+	}
+
+	q, err := parseFilter(args)
+	if err != nil {
+		return nil, err
+	}
+	showContext()
+
+	tasks, err := store.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	matched := q.Filter(tasks)
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no tasks match the filter: %s", q.Source)
+	}
+
+	for _, t := range matched {
+		ids = append(ids, t.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph [task ID]",
+	Short: "Show task dependencies",
+	Long: `Print the dependency tree of a task: the tasks it depends on, their own
+dependencies and so on. Without a task ID the trees of all tasks that nothing
+else depends on are printed.
+
+With --dot the graph is written in Graphviz DOT format instead. Edges point
+from a dependency to the task waiting for it, so the layout follows the order
+in which the work can be done.`,
+	Example: `  taskman graph 5
+  taskman graph
+  taskman graph 5 --dot | dot -Tpng -o deps.png`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: showGraph,
+}
+
+var graphDot bool
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.Flags().BoolVar(&graphDot, "dot", false, "Write the graph in Graphviz DOT format")
+}
+
+func showGraph(cmd *cobra.Command, args []string) error {
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	tasks, err := store.GetAll()
+	if err != nil {
+		return err
+	}
+	byID := task.IndexTasks(tasks)
+
+	var roots []*task.Task
+	if len(args) == 1 {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid task ID: %s", args[0])
+		}
+		t, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("task with ID %d not found", id)
+		}
+		roots = []*task.Task{t}
+	} else {
+		roots = dependencyRoots(tasks)
+	}
+
+	if graphDot {
+		writeDot(roots, byID)
+		return nil
+	}
+
+	if len(roots) == 0 {
+		ui.PrintInfo("No task dependencies found.")
+		return nil
+	}
+	ui.PrintTree(os.Stdout, roots, func(t *task.Task) []*task.Task {
+		return dependencies(t, byID)
+	}, ui.TaskLabel)
+	return nil
+}
+
+// dependencyRoots returns the tasks that have dependencies but that no other
+// task depends on, ordered by ID
+func dependencyRoots(tasks []*task.Task) []*task.Task {
+	dependedOn := make(map[int]bool)
+	for _, t := range tasks {
+		for _, dep := range t.DependsOn {
+			dependedOn[dep] = true
+		}
+	}
+
+	var roots []*task.Task
+	for _, t := range tasks {
+		if len(t.DependsOn) > 0 && !dependedOn[t.ID] {
+			roots = append(roots, t)
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool { return roots[i].ID < roots[j].ID })
+	return roots
+}
+
+// dependencies returns the tasks t depends on that still exist
+func dependencies(t *task.Task, byID map[int]*task.Task) []*task.Task {
+	var deps []*task.Task
+	for _, id := range t.DependsOn {
+		if d, ok := byID[id]; ok {
+			deps = append(deps, d)
+		}
+	}
+	return deps
+}
+
+// writeDot writes the tasks reachable from roots as a Graphviz digraph
+func writeDot(roots []*task.Task, byID map[int]*task.Task) {
+	var nodes []*task.Task
+	seen := make(map[int]bool)
+	var collect func(t *task.Task)
+	collect = func(t *task.Task) {
+		if seen[t.ID] {
+			return
+		}
+		seen[t.ID] = true
+		nodes = append(nodes, t)
+		for _, d := range dependencies(t, byID) {
+			collect(d)
+		}
+	}
+	for _, r := range roots {
+		collect(r)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	fmt.Println("digraph tasks {")
+	fmt.Println("  rankdir=LR;")
+	fmt.Println("  node [shape=box];")
+	for _, t := range nodes {
+		style := ""
+		if t.IsCompleted() {
+			style = ", style=dashed"
+		}
+		fmt.Printf("  %d [label=%s%s];\n", t.ID, dotQuote(fmt.Sprintf("#%d %s\n%s", t.ID, t.Description, t.Status)), style)
+	}
+	for _, t := range nodes {
+		for _, d := range dependencies(t, byID) {
+			fmt.Printf("  %d -> %d;\n", d.ID, t.ID)
+		}
+	}
+	fmt.Println("}")
+}
+
+// dotQuote quotes s as a DOT string
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
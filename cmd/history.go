@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [task ID]",
+	Short: "Show the history of operations",
+	Long: `Show the operations recorded in the history, most recent last. With a task ID,
+only operations that touched that task are shown. Undone operations are marked.
+The history is only recorded by the json storage driver.`,
+	Example: `  taskman history
+  taskman history 3
+  taskman history --limit 50`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: showHistory,
+}
+
+var historyLimit int
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "l", 20, "Number of operations to show (0 for all)")
+}
+
+func showHistory(cmd *cobra.Command, args []string) error {
+	id := 0
+	if len(args) == 1 {
+		var err error
+		if id, err = strconv.Atoi(args[0]); err != nil {
+			return fmt.Errorf("invalid task ID: %s", args[0])
+		}
+	}
+
+	history, err := openHistory()
+	if err != nil {
+		return err
+	}
+
+	entries, err := history.History(id)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		ui.PrintInfo("No history recorded yet.")
+		return nil
+	}
+
+	if historyLimit > 0 && len(entries) > historyLimit {
+		entries = entries[len(entries)-historyLimit:]
+	}
+
+	ui.DisplayHistoryTable(entries)
+	return nil
+}
@@ -0,0 +1,365 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/ical"
+	"github.com/vkhangstack/taskman/internal/output"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/taskwarrior"
+	"github.com/vkhangstack/taskman/internal/todotxt"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import tasks from other task managers",
+	Long: `Add the tasks of a file written by another task manager. Use - to read stdin.
+Creation and completion dates are kept. All tasks are added in one operation,
+so a single 'taskman undo' takes the import back.
+
+Formats:
+  todotxt       a todo.txt file. Tasks that are already in taskman, or that
+                appear twice in the file, are reported and skipped: a task is
+                a duplicate when its description and project match, and its
+                creation date too when the file has one. Lines that cannot be
+                read, such as one with a malformed due: date, are reported and
+                skipped too.
+  taskwarrior   the JSON of 'task export'.
+  ics           the VTODO components of an iCalendar file; events and other
+                components are ignored.
+
+Taskwarrior tasks and VTODOs are matched by UUID, or UID, so the same file can
+be imported again after changes on either side. When a task is already in
+taskman, --conflict decides:
+  newer   take the imported task when it was modified later, and report a
+          conflict when the local one was (default)
+  theirs  always take the imported task
+  ours    always keep the local task
+
+See 'taskman export --help' for how the formats map onto tasks.`,
+	Example: `  taskman import --format todotxt ~/todo.txt
+  taskman import --format todotxt --dry-run done.txt
+  cat todo.txt | taskman import --format todotxt -
+  task export | taskman import --format taskwarrior -
+  taskman import --format taskwarrior --conflict theirs tasks.json
+  taskman import --format ics ~/Downloads/reminders.ics`,
+	Args: cobra.ExactArgs(1),
+	RunE: importTasks,
+}
+
+var (
+	importFormat   string
+	importDryRun   bool
+	importConflict string
+)
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVar(&importFormat, "format", "", "Import format: todotxt, taskwarrior, ics")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Report what would be imported without changing anything")
+	importCmd.Flags().StringVar(&importConflict, "conflict", "newer", "How to resolve tasks changed on both sides: newer, theirs, ours")
+	importCmd.MarkFlagRequired("format")
+}
+
+// importedTask is a task read from an import file, with a description of
+// where in the file it came from and the UUIDs of the tasks it depends on
+type importedTask struct {
+	source  string
+	task    *task.Task
+	depends []string
+}
+
+// importStats counts what an import did with the tasks of the file
+type importStats struct {
+	added, updated, unchanged, duplicates, conflicts, invalid int
+}
+
+func importTasks(cmd *cobra.Command, args []string) error {
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open import file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	if err := validateConflict(importConflict); err != nil {
+		return err
+	}
+
+	var (
+		imported []importedTask
+		stats    importStats
+	)
+	switch importFormat {
+	case "todotxt":
+		entries, err := todotxt.Parse(r, time.Local)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.Err != nil {
+				ui.PrintWarning(fmt.Sprintf("Skipping line %d: %v", e.Line, e.Err))
+				stats.invalid++
+				continue
+			}
+			imported = append(imported, importedTask{source: fmt.Sprintf("line %d", e.Line), task: e.Task})
+		}
+	case "ics":
+		entries, err := ical.Parse(r, time.Local)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			imported = append(imported, importedTask{source: fmt.Sprintf("VTODO on line %d", e.Line), task: e.Task})
+		}
+	case "taskwarrior":
+		exported, err := taskwarrior.Read(r)
+		if err != nil {
+			return err
+		}
+		for i, tw := range exported {
+			source := fmt.Sprintf("task %d", i+1)
+			if tw.Status == "recurring" {
+				ui.PrintWarning(fmt.Sprintf("Skipping %s, a recurring task template: %s", source, tw.Description))
+				continue
+			}
+			t, err := tw.ToTask()
+			if err != nil {
+				return fmt.Errorf("failed to import %s: %w", source, err)
+			}
+			imported = append(imported, importedTask{source: source, task: t, depends: tw.Depends})
+		}
+	default:
+		return fmt.Errorf("invalid import format: %s. Valid formats are: todotxt, taskwarrior, ics", importFormat)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+	existing, err := store.GetAll()
+	if err != nil {
+		return err
+	}
+
+	// Remember every task under its key with and without the creation date, so
+	// that file entries without dates match too
+	seen := make(map[string]string)
+	byUUID := make(map[string]*task.Task)
+	for _, t := range existing {
+		seen[duplicateKey(t, false)] = "task " + ui.FormatID(t.ID)
+		seen[duplicateKey(t, true)] = "task " + ui.FormatID(t.ID)
+		byUUID[t.SyncUUID()] = t
+	}
+
+	var (
+		tasks   []*task.Task
+		linked  []importedTask
+		fromUID = make(map[string]string)
+	)
+	for _, it := range imported {
+		if uuid := it.task.UUID; uuid != "" {
+			if prev, ok := fromUID[uuid]; ok {
+				ui.PrintWarning(fmt.Sprintf("Skipping %s, a duplicate of %s: %s", it.source, prev, it.task.Description))
+				stats.duplicates++
+				continue
+			}
+			fromUID[uuid] = it.source
+
+			if local, ok := byUUID[uuid]; ok {
+				switch resolveConflict(local, it.task, importConflict) {
+				case conflictTheirs:
+					it.task = mergeImported(local, it.task)
+					stats.updated++
+				case conflictUnchanged:
+					stats.unchanged++
+					continue
+				case conflictOurs:
+					ui.PrintWarning(fmt.Sprintf("Conflict on %s: keeping task %s, modified %s here and %s in the file: %s",
+						it.source, ui.FormatID(local.ID), local.UpdatedAt.Local().Format("2006-01-02 15:04:05"),
+						it.task.UpdatedAt.Local().Format("2006-01-02 15:04:05"), local.Description))
+					stats.conflicts++
+					continue
+				}
+			} else {
+				stats.added++
+			}
+			byUUID[uuid] = it.task
+			tasks = append(tasks, it.task)
+			linked = append(linked, it)
+			continue
+		}
+
+		dated := !it.task.CreatedAt.IsZero()
+		key := duplicateKey(it.task, dated)
+		if prev, ok := seen[key]; ok {
+			ui.PrintWarning(fmt.Sprintf("Skipping %s, a duplicate of %s: %s", it.source, prev, it.task.Description))
+			stats.duplicates++
+			continue
+		}
+		seen[key] = it.source
+		if dated {
+			seen[duplicateKey(it.task, false)] = it.source
+		}
+		tasks = append(tasks, it.task)
+		stats.added++
+	}
+
+	res := output.NewResult("import")
+	if importDryRun {
+		if structuredOutput() {
+			// Tasks that would be added have no ID yet
+			for _, t := range tasks {
+				res.AddTask(t)
+			}
+			ui.PrintInfo("Would import: " + stats.String())
+			return writeResult(res)
+		}
+		for _, t := range tasks {
+			marker := "+"
+			if t.ID != 0 {
+				marker = "~"
+			}
+			fmt.Printf("  %s %s %s\n", marker, ui.FormatPriority(t.Priority), t.Description)
+		}
+		ui.PrintInfo("Would import: " + stats.String())
+		return nil
+	}
+	if len(tasks) == 0 {
+		ui.PrintInfo("Nothing to import: " + stats.String())
+		return finishResult(cmd, res)
+	}
+
+	// Dependencies name UUIDs, which only have IDs once the new tasks are added
+	var missing []string
+	link := func() error {
+		for _, it := range linked {
+			it.task.DependsOn = nil
+			for _, uuid := range it.depends {
+				dep, ok := byUUID[uuid]
+				if !ok {
+					missing = append(missing, fmt.Sprintf("%s depends on unknown task %s", it.source, uuid))
+					continue
+				}
+				it.task.DependsOn = append(it.task.DependsOn, dep.ID)
+			}
+		}
+		return nil
+	}
+	if len(linked) == 0 {
+		link = nil
+	}
+
+	if _, err := store.Import(tasks, link); err != nil {
+		return err
+	}
+	for _, m := range missing {
+		ui.PrintWarning("Dropped dependency: " + m)
+	}
+	for _, t := range tasks {
+		res.AddTask(t)
+	}
+	ui.PrintSuccess("Imported: " + stats.String())
+	return finishResult(cmd, res)
+}
+
+// String describes the counts, leaving out the ones that are zero
+func (s importStats) String() string {
+	parts := []string{fmt.Sprintf("%d added", s.added)}
+	for _, c := range []struct {
+		n    int
+		what string
+	}{
+		{s.updated, "updated"},
+		{s.unchanged, "unchanged"},
+		{s.duplicates, "duplicate(s) skipped"},
+		{s.conflicts, "conflict(s) kept local"},
+		{s.invalid, "invalid line(s) skipped"},
+	} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.what))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Outcomes of re-importing a task that is already in taskman
+const (
+	conflictUnchanged = iota
+	conflictTheirs
+	conflictOurs
+)
+
+// validateConflict checks the --conflict policy
+func validateConflict(policy string) error {
+	switch policy {
+	case "newer", "theirs", "ours":
+		return nil
+	}
+	return fmt.Errorf("invalid conflict policy: %s. Valid policies are: newer, theirs, ours", policy)
+}
+
+// resolveConflict decides between the local task and the imported version of
+// it. Modification times are compared to the second, the precision of the
+// Taskwarrior and iCalendar formats.
+func resolveConflict(local, incoming *task.Task, policy string) int {
+	ours := local.UpdatedAt.Truncate(time.Second)
+	theirs := incoming.UpdatedAt.Truncate(time.Second)
+
+	switch {
+	case ours.Equal(theirs):
+		return conflictUnchanged
+	case policy == "theirs":
+		return conflictTheirs
+	case theirs.IsZero():
+		// Without a modification time there is no telling which side changed
+		return conflictUnchanged
+	case policy == "ours", theirs.Before(ours):
+		return conflictOurs
+	default:
+		return conflictTheirs
+	}
+}
+
+// mergeImported returns the local task with the fields of the imported
+// version. Fields the import format has no place for, such as the parent,
+// recurrence and time log, are kept.
+func mergeImported(local, incoming *task.Task) *task.Task {
+	merged := *local
+	merged.UUID = incoming.UUID
+	merged.Description = incoming.Description
+	merged.Status = incoming.Status
+	merged.Priority = incoming.Priority
+	merged.Project = incoming.Project
+	merged.Tags = incoming.Tags
+	merged.Due = incoming.Due
+	merged.CreatedAt = incoming.CreatedAt
+	merged.UpdatedAt = incoming.UpdatedAt
+	merged.CompletedAt = incoming.CompletedAt
+	merged.Annotations = incoming.Annotations
+	if merged.Status != local.Status {
+		merged.Transitions = append(slices.Clone(local.Transitions),
+			task.Transition{From: local.Status, To: merged.Status, At: incoming.UpdatedAt})
+	}
+	return &merged
+}
+
+// duplicateKey identifies a task by its description and project, and its
+// creation day when dated is set
+func duplicateKey(t *task.Task, dated bool) string {
+	key := strings.ToLower(strings.Join(strings.Fields(t.Description), " ")) + "\x00" + t.Project
+	if dated {
+		key += "\x00" + t.CreatedAt.Local().Format("2006-01-02")
+	}
+	return key
+}
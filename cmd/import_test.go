@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+func TestDuplicateKey(t *testing.T) {
+	morning := time.Date(2026, 3, 4, 9, 0, 0, 0, time.Local)
+	base := &task.Task{Description: "Buy milk", Project: "home", CreatedAt: morning}
+
+	tests := []struct {
+		name  string
+		other *task.Task
+		dated bool
+		same  bool
+	}{
+		{"case", &task.Task{Description: "BUY Milk", Project: "home", CreatedAt: morning}, true, true},
+		{"whitespace", &task.Task{Description: "  Buy \t milk\n", Project: "home", CreatedAt: morning}, true, true},
+		{"same day", &task.Task{Description: "Buy milk", Project: "home", CreatedAt: morning.Add(8 * time.Hour)}, true, true},
+		{"other project", &task.Task{Description: "Buy milk", Project: "work", CreatedAt: morning}, true, false},
+		{"no project", &task.Task{Description: "Buy milk", CreatedAt: morning}, true, false},
+		{"other description", &task.Task{Description: "Buy milk and eggs", Project: "home", CreatedAt: morning}, true, false},
+		{"other day", &task.Task{Description: "Buy milk", Project: "home", CreatedAt: morning.AddDate(0, 0, 1)}, true, false},
+		{"other day undated", &task.Task{Description: "Buy milk", Project: "home", CreatedAt: morning.AddDate(0, 0, 1)}, false, true},
+		{"no creation date", &task.Task{Description: "Buy milk", Project: "home"}, false, true},
+	}
+	for _, tt := range tests {
+		same := duplicateKey(base, tt.dated) == duplicateKey(tt.other, tt.dated)
+		if same != tt.same {
+			t.Errorf("%s: duplicateKey matches = %v, want %v", tt.name, same, tt.same)
+		}
+	}
+
+	// A dated key never matches an undated one
+	if duplicateKey(base, true) == duplicateKey(base, false) {
+		t.Error("dated and undated keys are equal")
+	}
+}
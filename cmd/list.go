@@ -3,18 +3,73 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/vkhangstack/taskman/internal/dates"
 	"github.com/vkhangstack/taskman/internal/task"
 	"github.com/vkhangstack/taskman/internal/ui"
+	"os"
+	"strings"
+	"time"
 )
 
 var listCmd = &cobra.Command{
-	Use:   "list",
+	Use:   "list [filter]",
 	Short: "List all tasks",
-	Long:  `List all tasks in your task list. You can filter by status, priority, or tags.`,
+	Long: `List all tasks in your task list. You can filter by status, priority, or tags,
+or pass a filter expression:
+
+  +tag, -tag                 task has / lacks a tag
+  +OVERDUE, +DUETODAY, ...   virtual tags (OPEN, COMPLETED, DUE, RECURRING, TAGGED,
+                             BLOCKED, BLOCKING)
+  status:todo,pending        status is one of the values (also priority, id, recur)
+  parent:12, parent:none     subtasks of a task, or top-level tasks
+  project:work               tasks in the project or its subprojects
+  desc~deploy, "two words"   description contains the text
+  due.before:eow             dates: created, updated, due, completed
+                             with modifiers before, after, is, not
+  and, or, not, ( )          combine terms; adjacent terms are and-ed
+
+Quote the expression so the shell passes it as one argument. The same filters
+work with complete, delete, modify and process.
+
+--format prints every task with a Go text/template instead of the table. Besides
+the task fields ({{.ID}}, {{.Description}}, {{.Due}}, ...) templates can use:
+
+  upper, lower, trunc N, pad N, default TEXT   string helpers
+  join SEP, tags                               join .Tags ("tags" gives #a #b)
+  date LAYOUT, relative                        format times ("3d 2h ago", "in 5h 10m")
+  red, green, yellow, blue, cyan, bold         colors
+  formatID, formatStatus, formatPriority,      the helpers used by the tables
+  formatTags, formatDue, formatDescription
+
+Templates saved under formats: in ~/.taskman.yaml can be used by name.
+
+--sort takes comma-separated keys, each optionally followed by + (ascending) or
+- (descending): id, status, priority, urgency, project, description, due,
+created, updated, completed. Without a direction, priority and urgency put the
+highest first. --columns picks the table columns from id, status, priority,
+urgency, project, desc, tags, due, created, updated, completed, recur, parent,
+depends and spent. Descriptions are truncated to fit the terminal, and output
+longer than the terminal is paged through $PAGER.
+
+Defaults for --sort, --columns, --limit and --offset can be set under
+reports: list: in ~/.taskman.yaml.`,
 	Example: `  taskman list
   taskman list --status completed
   taskman list --priority high
-  taskman list --tags work,urgent`,
+  taskman list --tags work,urgent
+  taskman list --overdue
+  taskman list --due-before eow
+  taskman list --due-after today --due-before +7d
+  taskman list --tree
+  taskman list +OPEN --sort urgency
+  taskman list --sort priority-,created+ --limit 10 --offset 10
+  taskman list --columns id,status,desc,due
+  taskman list --project work
+  taskman list 'priority:high and (+work or +urgent) and -blocked'
+  taskman list 'created.after:2026-01-01 and desc~"deploy"'
+  taskman list --format '{{.ID}} {{.Priority | upper}} {{.Description | trunc 40}}'
+  taskman list +OPEN --format short`,
 	RunE: listTasks,
 }
 
@@ -23,6 +78,17 @@ var (
 	priorityFilter  string
 	tagsFilter      []string
 	completedFilter bool
+	overdueFilter   bool
+	dueBefore       string
+	dueAfter        string
+	listFormat      string
+	listTree        bool
+	projectFilter   string
+	listSort        string
+	listColumns     string
+	listLimit       int
+	listOffset      int
+	listNoPager     bool
 )
 
 func init() {
@@ -31,35 +97,66 @@ func init() {
 	listCmd.Flags().StringVarP(&priorityFilter, "priority", "p", "", "Filter tasks by priority (low, medium, high)")
 	listCmd.Flags().StringSliceVarP(&tagsFilter, "tags", "t", []string{}, "Filter tasks by tags (comma-separated)")
 	listCmd.Flags().BoolVar(&completedFilter, "completed", false, "Show only completed tasks")
+	listCmd.Flags().BoolVar(&overdueFilter, "overdue", false, "Show only open tasks past their due date")
+	listCmd.Flags().StringVar(&dueBefore, "due-before", "", "Show only tasks due on or before this date")
+	listCmd.Flags().StringVar(&dueAfter, "due-after", "", "Show only tasks due after this date")
+	listCmd.Flags().StringVarP(&projectFilter, "project", "P", "", "Show only tasks in this project or its subprojects")
+	listCmd.Flags().StringVarP(&listFormat, "format", "f", "", "Print each task with a Go template, or a template saved under formats: in the config")
+	addReportFlags(listCmd)
+}
 
+// addReportFlags adds the flags shared by list and report
+func addReportFlags(c *cobra.Command) {
+	c.Flags().BoolVar(&listTree, "tree", false, "Show subtasks indented below their parents")
+	c.Flags().StringVar(&listSort, "sort", "id-", "Sort by comma-separated keys, each followed by + (ascending) or - (descending)")
+	c.Flags().StringVar(&listColumns, "columns", strings.Join(ui.DefaultColumns, ","), "Comma-separated columns of the table")
+	c.Flags().IntVar(&listLimit, "limit", 0, "Show at most this many tasks (0 for all)")
+	c.Flags().IntVar(&listOffset, "offset", 0, "Skip this many tasks")
+	c.Flags().BoolVar(&listNoPager, "no-pager", false, "Do not page long output through $PAGER")
 }
+
 func listTasks(cmd *cobra.Command, args []string) error {
-	store, err := task.NewFileStore()
-	if err != nil {
-		return err
+	return runReport(cmd, "list", args)
+}
+
+// renderTasks prints the tasks with the --format template
+func renderTasks(tasks []*task.Task) error {
+	if structuredOutput() {
+		return fmt.Errorf("--format cannot be combined with --output")
+	}
+
+	text := listFormat
+	if saved, ok := viper.GetStringMapString("formats")[strings.ToLower(listFormat)]; ok {
+		text = saved
 	}
 
-	tasks, err := store.GetAll()
+	tmpl, err := ui.ParseTaskTemplate(text)
 	if err != nil {
 		return err
 	}
-	filteredTasks := filterTasks(tasks)
-	if len(filteredTasks) == 0 {
-		ui.PrintInfo("No tasks found matching the filters.")
-		return nil
-	}
-	for _, t := range filteredTasks {
-		fmt.Println(t.Description)
-		fmt.Println(len(t.Description))
-	}
-
-	ui.DisplayTasksTable(filteredTasks)
-	showSummary(filteredTasks)
-	return nil
+	return ui.RenderTasks(os.Stdout, tmpl, tasks)
 }
-func filterTasks(tasks []*task.Task) []*task.Task {
+
+func filterTasks(tasks []*task.Task) ([]*task.Task, error) {
 	var filteredTasks []*task.Task
 
+	now := time.Now()
+	var before, after *time.Time
+	if dueBefore != "" {
+		d, err := dates.Parse(dueBefore, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --due-before: %w", err)
+		}
+		before = &d
+	}
+	if dueAfter != "" {
+		d, err := dates.Parse(dueAfter, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --due-after: %w", err)
+		}
+		after = &d
+	}
+
 	for _, t := range tasks {
 		if statusFilter != "" && t.Status != statusFilter {
 			continue
@@ -67,6 +164,9 @@ func filterTasks(tasks []*task.Task) []*task.Task {
 		if priorityFilter != "" && t.Priority != priorityFilter {
 			continue
 		}
+		if projectFilter != "" && !t.InProject(projectFilter) {
+			continue
+		}
 		if len(tagsFilter) > 0 {
 			matched := false
 			for _, tag := range tagsFilter {
@@ -82,11 +182,20 @@ func filterTasks(tasks []*task.Task) []*task.Task {
 		if completedFilter && !t.IsCompleted() {
 			continue
 		}
+		if overdueFilter && !t.IsOverdue(now) {
+			continue
+		}
+		if before != nil && (t.Due == nil || t.Due.After(*before)) {
+			continue
+		}
+		if after != nil && (t.Due == nil || !t.Due.After(*after)) {
+			continue
+		}
 
 		filteredTasks = append(filteredTasks, t)
 	}
 
-	return filteredTasks
+	return filteredTasks, nil
 
 }
 
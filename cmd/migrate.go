@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copy tasks from tasks.json into the SQLite database",
+	Long: `Copy every task from a JSON tasks file into the SQLite database used by the
+sqlite storage driver. Task IDs are preserved. The migration refuses to run if the
+database already contains tasks.
+
+The sqlite driver does not record a history, so undo, redo and history are not
+available once you switch to it. The journal of the JSON store is not migrated.`,
+	Example: `  taskman migrate
+  taskman migrate --from ~/backup/tasks.json --to ~/.taskman/tasks.db`,
+	Args: cobra.NoArgs,
+	RunE: migrateTasks,
+}
+
+var (
+	migrateFrom string
+	migrateTo   string
+)
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().StringVar(&migrateFrom, "from", "", "JSON tasks file to read (default is ~/.taskman/tasks.json)")
+	migrateCmd.Flags().StringVar(&migrateTo, "to", "", "SQLite database to write (default is storage.path or ~/.taskman/tasks.db)")
+}
+
+func migrateTasks(cmd *cobra.Command, args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	from := migrateFrom
+	if from == "" {
+		from = filepath.Join(home, ".taskman", "tasks.json")
+	}
+	if _, err := os.Stat(from); err != nil {
+		return fmt.Errorf("failed to open tasks file: %w", err)
+	}
+
+	to := migrateTo
+	if to == "" {
+		to = filepath.Join(home, ".taskman", "tasks.db")
+		if storageDriver() == driverSQLite {
+			if to, err = storagePath(); err != nil {
+				return err
+			}
+		}
+	}
+
+	src, err := task.NewFileStoreAt(from)
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	dst, err := task.NewSQLiteStore(to)
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+	defer dst.Close()
+
+	count, err := dst.ImportFileStore(src)
+	if err != nil {
+		return err
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Migrated %d tasks from %s to %s", count, from, to))
+	if storageDriver() != driverSQLite {
+		ui.PrintInfo("Set storage.driver: sqlite in ~/.taskman.yaml to use the new database.")
+	}
+	return nil
+}
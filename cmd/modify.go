@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/dates"
+	"github.com/vkhangstack/taskman/internal/output"
+	"github.com/vkhangstack/taskman/internal/recur"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var modifyCmd = &cobra.Command{
+	Use:     "modify [task ID... | filter]",
+	Aliases: []string{"mod"},
+	Short:   "Change existing tasks",
+	Long: `Change the description, priority, status, tags, due date or recurrence of one or
+more tasks, given by ID or by a filter expression. Only the given flags are changed. Use 'none'
+with --due, --recur, --project or --parent to clear them. See 'taskman list --help' for the filter syntax.`,
+	Example: `  taskman modify 1 --desc "Call the dentist"
+  taskman modify 2 3 --priority high --add-tag urgent
+  taskman modify 4 --remove-tag later --status in_progress
+  taskman modify 5 --due fri
+  taskman modify 6 --due none
+  taskman modify 7 --parent 12
+  taskman modify '+inbox and priority:low' --add-tag someday`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: modifyTasks,
+}
+
+var (
+	modifyDesc       string
+	modifyPriority   string
+	modifyStatus     string
+	modifyAddTags    []string
+	modifyRemoveTags []string
+	modifyDue        string
+	modifyRecur      string
+	modifyParent     string
+	modifyProject    string
+)
+
+func init() {
+	rootCmd.AddCommand(modifyCmd)
+
+	modifyCmd.Flags().StringVar(&modifyDesc, "desc", "", "New description")
+	modifyCmd.Flags().StringVarP(&modifyPriority, "priority", "p", "", "New priority (low, medium, high)")
+	modifyCmd.Flags().StringVarP(&modifyStatus, "status", "s", "", "New status (todo, pending, in_progress, completed, archived, deleted)")
+	modifyCmd.Flags().StringSliceVar(&modifyAddTags, "add-tag", []string{}, "Tags to add")
+	modifyCmd.Flags().StringSliceVar(&modifyRemoveTags, "remove-tag", []string{}, "Tags to remove")
+	modifyCmd.Flags().StringVarP(&modifyDue, "due", "d", "", "New due date, or 'none' to clear it")
+	modifyCmd.Flags().StringVarP(&modifyRecur, "recur", "r", "", "New recurrence rule, or 'none' to stop recurring")
+	modifyCmd.Flags().StringVarP(&modifyProject, "project", "P", "", "New project, or 'none' to remove it from its project")
+	modifyCmd.Flags().StringVar(&modifyParent, "parent", "", "ID of the new parent task, or 'none' to make it a top-level task")
+}
+
+func modifyTasks(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	// Global flags such as --output do not count as changes
+	changes := 0
+	for _, name := range []string{"desc", "priority", "status", "add-tag", "remove-tag", "due", "recur", "project", "parent"} {
+		if flags.Changed(name) {
+			changes++
+		}
+	}
+	if changes == 0 {
+		return fmt.Errorf("nothing to modify: use --desc, --priority, --status, --add-tag, --remove-tag, --due, --recur, --project or --parent")
+	}
+
+	parent := 0
+	if flags.Changed("parent") && modifyParent != "none" {
+		id, err := strconv.Atoi(modifyParent)
+		if err != nil {
+			return fmt.Errorf("invalid parent task ID: %s", modifyParent)
+		}
+		parent = id
+	}
+
+	var due *time.Time
+	if flags.Changed("due") && modifyDue != "none" {
+		d, err := dates.Parse(modifyDue, time.Now())
+		if err != nil {
+			return err
+		}
+		due = &d
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	ids, err := resolveTargets(store, args)
+	if err != nil {
+		return err
+	}
+
+	res := output.NewResult("modify")
+	for _, id := range ids {
+		t, err := store.GetByID(id)
+		if err != nil {
+			reportError(res, id, fmt.Errorf("failed to retrieve task with ID %d: %w", id, err))
+			continue
+		}
+
+		if flags.Changed("desc") {
+			t.Description = strings.TrimSpace(modifyDesc)
+		}
+		if flags.Changed("priority") {
+			t.Priority = modifyPriority
+		}
+		// Completion goes through the store so recurring tasks spawn their next occurrence
+		complete := flags.Changed("status") && modifyStatus == task.StatusCompleted && !t.IsCompleted()
+		if flags.Changed("status") && t.Status != modifyStatus && !complete {
+			t.MarkStatus(modifyStatus)
+		}
+		for _, tag := range modifyAddTags {
+			t.AddTag(strings.TrimSpace(tag))
+		}
+		for _, tag := range modifyRemoveTags {
+			t.RemoveTag(strings.TrimSpace(tag))
+		}
+		if flags.Changed("due") {
+			t.Due = due
+		}
+		if flags.Changed("parent") {
+			t.ParentID = parent
+		}
+		if flags.Changed("project") {
+			t.Project = strings.TrimSpace(modifyProject)
+			if modifyProject == "none" {
+				t.Project = ""
+			}
+		}
+		if flags.Changed("recur") {
+			t.Recur = modifyRecur
+			if modifyRecur == "none" {
+				t.Recur = ""
+			}
+		}
+
+		if err := validateTask(t); err != nil {
+			reportError(res, id, fmt.Errorf("invalid task with ID %d: %w", id, err))
+			continue
+		}
+		if err := store.Update(t); err != nil {
+			reportError(res, id, fmt.Errorf("failed to update task with ID %d: %w", id, err))
+			continue
+		}
+		if complete {
+			if err := store.Complete(id); err != nil {
+				reportError(res, id, fmt.Errorf("failed to complete task with ID %d: %w", id, err))
+				continue
+			}
+			if t, err = store.GetByID(id); err != nil {
+				reportError(res, id, fmt.Errorf("failed to retrieve task with ID %d: %w", id, err))
+				continue
+			}
+		}
+		res.AddTask(t)
+	}
+
+	if !structuredOutput() && len(res.Tasks) > 0 {
+		ui.PrintSuccess(fmt.Sprintf("%d task(s) modified successfully!", len(res.Tasks)))
+	}
+	return finishResult(cmd, res)
+}
+
+// validateTask checks the user-editable fields of a task
+func validateTask(t *task.Task) error {
+	if strings.TrimSpace(t.Description) == "" {
+		return fmt.Errorf("description cannot be empty")
+	}
+	if !isValidPriority(t.Priority) {
+		return fmt.Errorf("invalid priority: %s. Valid priorities are: low, medium, high", t.Priority)
+	}
+	if !isValidStatus(t.Status) {
+		return fmt.Errorf("invalid status: %s. Valid statuses are: todo, pending, in_progress, completed, archived, deleted", t.Status)
+	}
+	for _, tag := range t.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("tag cannot be empty")
+		}
+	}
+	if t.Recur != "" {
+		if _, err := recur.Parse(t.Recur); err != nil {
+			return err
+		}
+	}
+	return task.ValidateProject(t.Project)
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/vkhangstack/taskman/internal/output"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var nextCmd = &cobra.Command{
+	Use:   "next [filter]",
+	Short: "Show the most urgent open tasks",
+	Long: `Show the open tasks with the highest urgency, most urgent first. Urgency adds up
+weights for the priority, the age of a task, how close it is to its due date,
+tags such as +urgent, being in progress, and blocking other tasks. Blocked tasks
+are pushed down.
+
+The weights can be set under urgency: in ~/.taskman.yaml:
+
+  urgency:
+    priority_high: 6
+    priority_medium: 3.9
+    priority_low: 1.8
+    age: 2            # reached when a task is age_max days old
+    age_max: 365
+    due: 12           # reached a week after the due date
+    in_progress: 4
+    blocked: -5
+    blocking: 8
+    tags:
+      urgent: 5`,
+	Example: `  taskman next
+  taskman next -n 10
+  taskman next +work`,
+	RunE: showNext,
+}
+
+var nextLimit int
+
+func init() {
+	rootCmd.AddCommand(nextCmd)
+	nextCmd.Flags().IntVarP(&nextLimit, "limit", "n", 5, "Number of tasks to show")
+}
+
+func showNext(cmd *cobra.Command, args []string) error {
+	if nextLimit < 1 {
+		return fmt.Errorf("--limit must be at least 1")
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	q, err := parseFilter(args)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := store.GetAll()
+	if err != nil {
+		return err
+	}
+	urgency, err := urgencyScores(tasks)
+	if err != nil {
+		return err
+	}
+
+	var open []*task.Task
+	for _, t := range q.Filter(tasks) {
+		if t.IsOpen() {
+			open = append(open, t)
+		}
+	}
+	task.SortByUrgency(open, urgency)
+	if len(open) > nextLimit {
+		open = open[:nextLimit]
+	}
+
+	if structuredOutput() {
+		res := output.NewResult("next")
+		for _, t := range open {
+			res.AddTask(t)
+		}
+		return writeResult(res)
+	}
+	showContext()
+	if len(open) == 0 {
+		ui.PrintInfo("Nothing to do: no open tasks found.")
+		return nil
+	}
+
+	ui.DisplayTasksTable(open, ui.TableOptions{Urgency: urgency})
+	return nil
+}
+
+// urgencyScores scores the tasks with the weights configured under urgency:,
+// falling back to the defaults for weights that are not set
+func urgencyScores(tasks []*task.Task) (map[int]float64, error) {
+	weights := task.DefaultUrgencyWeights()
+	if err := viper.UnmarshalKey("urgency", &weights); err != nil {
+		return nil, fmt.Errorf("invalid urgency weights in config: %w", err)
+	}
+	return task.UrgencyScores(tasks, weights, time.Now()), nil
+}
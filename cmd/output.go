@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/output"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var (
+	outputFlag   string
+	outputFormat output.Format
+)
+
+// setupOutput validates --output and, for structured formats, moves messages
+// to stderr and turns colors off so that stdout only carries the result
+func setupOutput(cmd *cobra.Command, args []string) error {
+	format, err := output.ParseFormat(outputFlag)
+	if err != nil {
+		return err
+	}
+	outputFormat = format
+
+	if !structuredOutput() {
+		return nil
+	}
+	if !supportsOutput(cmd) {
+		return fmt.Errorf("%s does not support --output", cmd.CommandPath())
+	}
+
+	ui.Messages = os.Stderr
+	color.NoColor = true
+	return nil
+}
+
+// structuredOutput returns true if a machine-readable format was requested
+func structuredOutput() bool {
+	return outputFormat != output.Text
+}
+
+// supportsOutput returns true if the command reports an output.Result
+func supportsOutput(cmd *cobra.Command) bool {
+	for _, c := range []*cobra.Command{
+		addCmd, listCmd, showCmd, completeCmd, deleteCmd, modifyCmd,
+		statusCmd, tagAddCmd, tagRemoveCmd, processingCmd, undoCmd, redoCmd,
+		dependCmd, undependCmd, startCmd, stopCmd, logCmd, timesheetCmd, nextCmd, reportCmd,
+		recurStopCmd, projectRenameCmd, editCmd, importCmd,
+	} {
+		if c == cmd {
+			return true
+		}
+	}
+	return false
+}
+
+// errTasksFailed is returned by commands that failed for some of their tasks,
+// after reporting those failures
+var errTasksFailed = errors.New("some tasks failed")
+
+// finishResult writes the result in structured output mode, and returns
+// errTasksFailed without printing it again if any task failed, so that the
+// command exits with a non-zero status
+func finishResult(cmd *cobra.Command, res *output.Result) error {
+	if structuredOutput() {
+		if err := writeResult(res); err != nil {
+			return err
+		}
+	}
+	if res.OK {
+		return nil
+	}
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return errTasksFailed
+}
+
+// writeResult writes the result to stdout in the requested format
+func writeResult(res *output.Result) error {
+	return output.Write(os.Stdout, outputFormat, res)
+}
+
+// addJournalTasks adds the tasks left by undo or redo entries to the result
+func addJournalTasks(res *output.Result, entries ...*task.JournalEntry) {
+	for _, e := range entries {
+		for _, c := range e.Changes {
+			if c.After != nil {
+				res.AddTask(c.After)
+			}
+		}
+	}
+}
+
+// reportError records a failure for one task in the result. In text mode it
+// is printed straight away instead.
+func reportError(res *output.Result, id int, err error) {
+	res.AddError(id, err)
+	if !structuredOutput() {
+		ui.PrintError(err.Error())
+	}
+}
+
+// writeTaskResult writes a result holding the current state of a single task
+func writeTaskResult(command string, store task.Store, id int) error {
+	res := output.NewResult(command)
+	t, err := store.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve task with ID %d: %w", id, err)
+	}
+	res.AddTask(t)
+	return writeResult(res)
+}
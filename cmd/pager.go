@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+// withPager runs render with ui.Out pointed at a buffer and pipes the result
+// through $PAGER when it is longer than the terminal. Without a terminal or a
+// pager, or when paging is disabled, render writes to stdout directly.
+func withPager(enabled bool, render func() error) error {
+	pager := strings.Fields(os.Getenv("PAGER"))
+	_, height, ok := ui.TerminalSize()
+	if !enabled || !ok || len(pager) == 0 {
+		return render()
+	}
+
+	var buf bytes.Buffer
+	ui.Out = &buf
+	err := render()
+	ui.Out = os.Stdout
+	if err != nil {
+		return err
+	}
+
+	if bytes.Count(buf.Bytes(), []byte("\n")) < height {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+
+	c := exec.Command(pager[0], pager[1:]...)
+	c.Stdin = &buf
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if os.Getenv("LESS") == "" {
+		// Keep colors and quit less when the output fits after all
+		c.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("pager %s failed: %w", pager[0], err)
+	}
+	return nil
+}
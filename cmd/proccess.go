@@ -3,19 +3,20 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/output"
 	"github.com/vkhangstack/taskman/internal/task"
 	"github.com/vkhangstack/taskman/internal/ui"
-	"strconv"
-	"time"
 )
 
 var processingCmd = &cobra.Command{
-	Use:   "process",
+	Use:   "process [task ID... | filter]",
 	Short: "Process tasks",
-	Long:  `Process tasks based on their status, priority, or tags. This command allows you to perform batch operations on tasks.`,
+	Long: `Process tasks based on their status, priority, or tags. This command allows you to perform batch operations on tasks.
+Tasks are given by ID or by a filter expression; see 'taskman list --help' for the filter syntax.`,
 	Example: `  taskman process 1
   taskman process 2 --priority high
-  taskman process 3 4 5 -p medium`,
+  taskman process 3 4 5 -p medium
+  taskman process 'status:todo and +sprint'`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: processingTask,
 }
@@ -23,58 +24,71 @@ var processingCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(processingCmd)
 
-	processingCmd.Flags().StringP("priority", "p", "", "Filter tasks by priority (low, medium, high)")
+	processingCmd.Flags().StringVarP(&processPriority, "priority", "p", "", "Set the priority of the processed tasks (low, medium, high)")
 }
 
-func processingTask(cmd *cobra.Command, args []string) error {
-	var ids []int
-	for _, arg := range args {
-		id, err := strconv.Atoi(arg)
-		if err != nil {
-			return fmt.Errorf("invalid task ID: %s", arg)
-		}
-		ids = append(ids, id)
-	}
+var processPriority string
 
-	if !isValidPriority(priority) {
-		return fmt.Errorf("invalid priority: %s. Valid priorities are: low, medium, high", priority)
+func processingTask(cmd *cobra.Command, args []string) error {
+	if processPriority != "" && !isValidPriority(processPriority) {
+		return fmt.Errorf("invalid priority: %s. Valid priorities are: low, medium, high", processPriority)
 	}
 
-	store, err := task.NewFileStore()
+	store, err := openStore()
 
 	if err != nil {
 		return fmt.Errorf("failed to initialize task store: %w", err)
 	}
+
+	ids, err := resolveTargets(store, args)
+	if err != nil {
+		return err
+	}
+	res := output.NewResult("process")
 	for _, id := range ids {
 		taskRecord, err := store.GetByID(id)
 		if err != nil {
-			return fmt.Errorf("failed to retrieve task with ID %d: %w", id, err)
+			reportError(res, id, fmt.Errorf("failed to retrieve task with ID %d: %w", id, err))
+			continue
 		}
 
 		switch taskRecord.Status {
 		case task.StatusTodo:
-			fmt.Printf("Processing pending task: %s\n", taskRecord.Description)
+			blockers, err := blockedBy(store, taskRecord)
+			if err != nil {
+				return err
+			}
+			if len(blockers) > 0 {
+				reportError(res, id, fmt.Errorf("task %d is blocked by unfinished tasks %s", id, formatIDs(blockers)))
+				continue
+			}
+			fmt.Fprintf(ui.Messages, "Processing pending task: %s\n", taskRecord.Description)
 			// Add your processing logic here
-			taskRecord.Status = task.StatusInProgress
-			taskRecord.UpdatedAt = time.Now()
-			if priority != "" {
-				taskRecord.Priority = priority
+			taskRecord.MarkInProgress()
+			if processPriority != "" {
+				taskRecord.Priority = processPriority
 			}
 			if err := store.Update(taskRecord); err != nil {
-				return fmt.Errorf("failed to update task with ID %d: %w", id, err)
+				reportError(res, id, fmt.Errorf("failed to update task with ID %d: %w", id, err))
+				continue
 			}
-			fmt.Printf("Task %d is now in progress.\n", id)
+			fmt.Fprintf(ui.Messages, "Task %d is now in progress.\n", id)
 		case task.StatusInProgress:
-			fmt.Printf("Task %d is already in progress.\n", id)
+			fmt.Fprintf(ui.Messages, "Task %d is already in progress.\n", id)
 		case task.StatusCompleted:
-			fmt.Printf("Task %d is already completed.\n", id)
+			fmt.Fprintf(ui.Messages, "Task %d is already completed.\n", id)
 		case task.StatusDeleted:
-			fmt.Printf("Task %d is deleted and cannot be processed.\n", id)
+			reportError(res, id, fmt.Errorf("task %d is deleted and cannot be processed", id))
+			continue
 		default:
-			fmt.Printf("Unknown status for task %d: %s\n", id, taskRecord.Status)
+			reportError(res, id, fmt.Errorf("unknown status for task %d: %s", id, taskRecord.Status))
+			continue
 		}
+		res.AddTask(taskRecord)
 	}
-	ui.PrintSuccess("Tasks processed successfully!")
-	return nil
 
+	if !structuredOutput() && len(res.Tasks) > 0 {
+		ui.PrintSuccess("Tasks processed successfully!")
+	}
+	return finishResult(cmd, res)
 }
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+// useTestStore points the commands at a new tasks file and silences their
+// messages for the rest of the test
+func useTestStore(t *testing.T) task.Store {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	viper.Set("storage.path", path)
+	messages := ui.Messages
+	ui.Messages = io.Discard
+	t.Cleanup(func() {
+		viper.Set("storage.path", "")
+		ui.Messages = messages
+	})
+
+	store, err := task.NewFileStoreAt(path)
+	if err != nil {
+		t.Fatalf("NewFileStoreAt: %v", err)
+	}
+	return store
+}
+
+func TestProcessRefusesBlockedTasks(t *testing.T) {
+	store := useTestStore(t)
+	for _, deps := range [][]int{nil, {1}} {
+		if _, err := store.Add(&task.Task{Description: "task", Status: task.StatusTodo, Priority: task.PriorityMedium, DependsOn: deps}); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	// Task 2 waits for task 1, which is still open
+	if err := processingTask(processingCmd, []string{"2"}); !errors.Is(err, errTasksFailed) {
+		t.Fatalf("process 2 = %v, want errTasksFailed", err)
+	}
+	if blocked, _ := store.GetByID(2); blocked.Status != task.StatusTodo {
+		t.Errorf("blocked task 2 is %s, want todo", blocked.Status)
+	}
+
+	if err := processingTask(processingCmd, []string{"1"}); err != nil {
+		t.Fatalf("process 1: %v", err)
+	}
+	if err := store.Complete(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := processingTask(processingCmd, []string{"2"}); err != nil {
+		t.Fatalf("process 2 once task 1 is completed: %v", err)
+	}
+	if unblocked, _ := store.GetByID(2); unblocked.Status != task.StatusInProgress {
+		t.Errorf("task 2 is %s, want in_progress", unblocked.Status)
+	}
+}
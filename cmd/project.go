@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/output"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var projectsCmd = &cobra.Command{
+	Use:   "projects [project]",
+	Short: "List projects with their progress",
+	Long: `List every project with its open and completed tasks and its progress. A project
+includes the tasks of its subprojects, so work covers work.backend and work.frontend.
+Give a project to only show it and its subprojects.`,
+	Example: `  taskman projects
+  taskman projects work`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: listProjects,
+}
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Manage projects",
+	Long:  `Manage the projects tasks belong to. Projects use dots for subprojects, such as work.backend.api.`,
+	Example: `  taskman project rename work.api work.backend.api
+  taskman projects`,
+}
+
+var projectRenameCmd = &cobra.Command{
+	Use:   "rename [old project] [new project]",
+	Short: "Rename a project",
+	Long: `Move every task of a project, including those in its subprojects, to a new project.
+Renaming work.api to work.backend.api also moves work.api.v2 to work.backend.api.v2.`,
+	Example: `  taskman project rename work.api work.backend.api`,
+	Args:    cobra.ExactArgs(2),
+	RunE:    renameProject,
+}
+
+func init() {
+	rootCmd.AddCommand(projectsCmd)
+	rootCmd.AddCommand(projectCmd)
+	projectCmd.AddCommand(projectRenameCmd)
+}
+
+func listProjects(cmd *cobra.Command, args []string) error {
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	q, err := parseFilter(nil)
+	if err != nil {
+		return err
+	}
+	all, err := store.GetAll()
+	if err != nil {
+		return err
+	}
+	tasks := q.Filter(all)
+
+	showContext()
+	var projects []string
+	for _, p := range task.Projects(tasks) {
+		if len(args) == 0 || task.IsSubproject(p, args[0]) {
+			projects = append(projects, p)
+		}
+	}
+	if len(projects) == 0 {
+		ui.PrintInfo("No projects found.")
+		return nil
+	}
+
+	for _, p := range projects {
+		var inProject []*task.Task
+		for _, t := range tasks {
+			if t.InProject(p) {
+				inProject = append(inProject, t)
+			}
+		}
+		ui.DisplayTasksSummaryTitled("Project "+ui.CyanBold.Sprint(p), inProject)
+	}
+	return nil
+}
+
+func renameProject(cmd *cobra.Command, args []string) error {
+	from, to := args[0], args[1]
+	for _, p := range []string{from, to} {
+		if p == "" {
+			return fmt.Errorf("project cannot be empty")
+		}
+		if err := task.ValidateProject(p); err != nil {
+			return err
+		}
+	}
+	if task.IsSubproject(to, from) && to != from {
+		return fmt.Errorf("cannot move project %s into its own subproject %s", from, to)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	// Note which tasks move, to report them in structured output
+	tasks, err := store.GetAll()
+	if err != nil {
+		return err
+	}
+	var movedIDs []int
+	for _, t := range tasks {
+		if _, ok := task.MoveProject(t.Project, from, to); ok {
+			movedIDs = append(movedIDs, t.ID)
+		}
+	}
+
+	moved, err := store.RenameProject(from, to)
+	if err != nil {
+		return fmt.Errorf("failed to rename project %s: %w", from, err)
+	}
+	if structuredOutput() {
+		res := output.NewResult("project rename")
+		for _, id := range movedIDs {
+			t, err := store.GetByID(id)
+			if err != nil {
+				reportError(res, id, fmt.Errorf("failed to retrieve task with ID %d: %w", id, err))
+				continue
+			}
+			res.AddTask(t)
+		}
+		return finishResult(cmd, res)
+	}
+	if moved == 0 {
+		ui.PrintWarning(fmt.Sprintf("No tasks found in project %s", from))
+		return nil
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Moved %d task(s) from %s to %s", moved, from, to))
+	return nil
+}
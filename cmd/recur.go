@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/output"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var recurCmd = &cobra.Command{
+	Use:   "recur",
+	Short: "Manage recurring tasks",
+	Long: `Manage recurring task series. A recurring task is created with 'taskman add --recur';
+completing it adds the next occurrence with the same description, priority and tags.`,
+	Example: `  taskman recur list
+  taskman recur list --all
+  taskman recur stop 12`,
+}
+
+var recurListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List recurring series",
+	Args:    cobra.NoArgs,
+	RunE:    listSeries,
+}
+
+var recurStopCmd = &cobra.Command{
+	Use:   "stop [task ID...]",
+	Short: "Stop recurring series",
+	Long: `Stop the series a task belongs to. The open occurrence is kept, but completing it
+no longer adds a new one. Any task ID of the series can be given.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: stopSeries,
+}
+
+var allSeries bool
+
+func init() {
+	rootCmd.AddCommand(recurCmd)
+	recurCmd.AddCommand(recurListCmd)
+	recurCmd.AddCommand(recurStopCmd)
+
+	recurListCmd.Flags().BoolVarP(&allSeries, "all", "a", false, "Include stopped series")
+}
+
+func listSeries(cmd *cobra.Command, args []string) error {
+	store, err := openStore()
+	if err != nil {
+		return err
+	}
+
+	tasks, err := store.GetAll()
+	if err != nil {
+		return err
+	}
+
+	var series []*task.Series
+	for _, s := range task.GroupSeries(tasks) {
+		if allSeries || s.IsActive() {
+			series = append(series, s)
+		}
+	}
+	if len(series) == 0 {
+		ui.PrintInfo("No recurring tasks found.")
+		return nil
+	}
+
+	ui.DisplaySeriesTable(series)
+	return nil
+}
+
+func stopSeries(cmd *cobra.Command, args []string) error {
+	var ids []int
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid task ID: %s", arg)
+		}
+		ids = append(ids, id)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	tasks, err := store.GetAll()
+	if err != nil {
+		return err
+	}
+
+	res := output.NewResult("recur stop")
+	for _, id := range ids {
+		taskRecord, err := store.GetByID(id)
+		if err != nil {
+			reportError(res, id, fmt.Errorf("failed to retrieve task with ID %d: %w", id, err))
+			continue
+		}
+		if !taskRecord.IsRecurring() && taskRecord.RecurParent == 0 {
+			ui.PrintWarning(fmt.Sprintf("Task %d is not recurring", id))
+			continue
+		}
+
+		seriesID := taskRecord.SeriesID()
+		stopped := true
+		for _, t := range tasks {
+			if t.SeriesID() != seriesID || !t.IsRecurring() || !t.IsOpen() {
+				continue
+			}
+			t.Recur = ""
+			if err := store.Update(t); err != nil {
+				reportError(res, t.ID, fmt.Errorf("failed to update task with ID %d: %w", t.ID, err))
+				stopped = false
+				continue
+			}
+			res.AddTask(t)
+		}
+		if stopped && !structuredOutput() {
+			ui.PrintSuccess(fmt.Sprintf("Stopped recurring series %d", seriesID))
+		}
+	}
+
+	return finishResult(cmd, res)
+}
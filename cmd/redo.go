@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var redoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Redo undone operations",
+	Long: `Reapply operations reverted by 'taskman undo', most recently undone first.
+Making any other change to your tasks clears the operations that can be redone.
+Like undo, redo needs the json storage driver.`,
+	Example: `  taskman redo
+  taskman redo --count 2`,
+	Args: cobra.NoArgs,
+	RunE: redoTask,
+}
+
+var (
+	redoCount int
+	redoForce bool
+)
+
+func init() {
+	rootCmd.AddCommand(redoCmd)
+
+	redoCmd.Flags().IntVarP(&redoCount, "count", "n", 1, "Number of operations to redo")
+	redoCmd.Flags().BoolVarP(&redoForce, "force", "f", false, "Redo even if the tasks were modified since")
+}
+
+func redoTask(cmd *cobra.Command, args []string) error {
+	if redoCount < 1 {
+		return fmt.Errorf("invalid count: %d", redoCount)
+	}
+
+	history, err := openHistory()
+	if err != nil {
+		return err
+	}
+
+	entries, err := history.Redo(redoCount, redoForce)
+	if err != nil {
+		return fmt.Errorf("failed to redo: %w", err)
+	}
+	return reportJournalEntries("redo", "Redid", entries)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/dates"
+	"github.com/vkhangstack/taskman/internal/report"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var renderCmd = &cobra.Command{
+	Use:   "render [filter]",
+	Short: "Write a status report as Markdown or HTML",
+	Long: `Write a status report of the tasks to stdout, for status mails and wikis. The
+report has the summary counts of 'taskman list' and sections for the tasks
+completed since --since, overdue, in progress, to do and pending, each grouped
+by priority. Give a filter to only report matching tasks.
+
+HTML reports are a single file with inline CSS. The documents come from
+templates built into taskman. To change them, save a template as
+~/.taskman/templates/report.md.tmpl or report.html.tmpl; --print-template
+prints the built-in one to start from.`,
+	Example: `  taskman report render > status.md
+  taskman report render --format html --since 14d > status.html
+  taskman report render project:web --since 2026-03-01
+  taskman report render --format html --print-template > ~/.taskman/templates/report.html.tmpl`,
+	RunE: renderReport,
+}
+
+var (
+	renderFormat        string
+	renderSince         string
+	renderTitle         string
+	renderPrintTemplate bool
+)
+
+func init() {
+	reportCmd.AddCommand(renderCmd)
+
+	renderCmd.Flags().StringVar(&renderFormat, "format", report.Markdown, "Document format: md, html")
+	renderCmd.Flags().StringVar(&renderSince, "since", "7d", "Start of the period for completed tasks, as a date or a number of days, weeks or months back")
+	renderCmd.Flags().StringVar(&renderTitle, "title", "Status Report", "Title of the report")
+	renderCmd.Flags().BoolVar(&renderPrintTemplate, "print-template", false, "Print the built-in template of the format instead of a report")
+}
+
+func renderReport(cmd *cobra.Command, args []string) error {
+	if renderFormat != report.Markdown && renderFormat != report.HTML {
+		return fmt.Errorf("invalid report format: %s. Valid formats are: md, html", renderFormat)
+	}
+	if renderPrintTemplate {
+		text, err := report.Template(renderFormat)
+		if err != nil {
+			return err
+		}
+		fmt.Print(text)
+		return nil
+	}
+
+	now := time.Now()
+	since, err := sinceDate(renderSince, now)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+	q, err := parseFilter(args)
+	if err != nil {
+		return err
+	}
+	// stdout carries the report, so the context goes to stderr
+	ui.Messages = os.Stderr
+	showContext()
+	tasks, err := store.GetAll()
+	if err != nil {
+		return err
+	}
+
+	dir, err := templateDir()
+	if err != nil {
+		return err
+	}
+	return report.Render(os.Stdout, renderFormat, dir, report.Build(renderTitle, q.Filter(tasks), since, now))
+}
+
+// backOffset matches offsets without a sign, such as 7d or 2w, which --since
+// counts back from now
+var backOffset = regexp.MustCompile(`^\d+[hdwmy]$`)
+
+// sinceDate resolves a --since value to the start of its day
+func sinceDate(s string, now time.Time) (time.Time, error) {
+	expr := strings.TrimSpace(s)
+	if backOffset.MatchString(expr) {
+		expr = "-" + expr
+	}
+	t, err := dates.Parse(expr, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since date: %w", err)
+	}
+	return dates.StartOfDay(t), nil
+}
+
+// templateDir returns the directory of user templates, ~/.taskman/templates
+func templateDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".taskman", "templates"), nil
+}
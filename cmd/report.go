@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/vkhangstack/taskman/internal/output"
+	"github.com/vkhangstack/taskman/internal/query"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report [name] [filter]",
+	Short: "Run a report saved in the config",
+	Long: `Run a report defined under reports: in ~/.taskman.yaml. A report combines a
+filter with the sort order, columns and limit of list, and can also be run as
+'taskman <name>'. Arguments after the name narrow the report further, and flags
+override its settings. Without a name, the configured reports are listed.
+
+  reports:
+    today:
+      description: Open work due today
+      filter: +OPEN and (+DUETODAY or +OVERDUE)
+      sort: urgency-
+      columns: id,priority,desc,due
+      limit: 10
+
+Settings under reports: list: are the defaults of 'taskman list'. For status
+reports as Markdown or HTML documents, see 'taskman report render'.`,
+	Example: `  taskman report
+  taskman report today
+  taskman today +work
+  taskman today --limit 3
+  taskman report render --format html > status.html`,
+	RunE: showReport,
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	addReportFlags(reportCmd)
+}
+
+func showReport(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return listReports()
+	}
+
+	name := args[0]
+	if !viper.IsSet("reports." + name) {
+		msg := fmt.Sprintf("unknown command or report %q", name)
+		if suggestions := cmd.Root().SuggestionsFor(name); len(suggestions) > 0 {
+			msg += fmt.Sprintf("; did you mean %s?", strings.Join(suggestions, " or "))
+		}
+		return fmt.Errorf("%s (run 'taskman report' to see the reports)", msg)
+	}
+	return runReport(cmd, name, args[1:])
+}
+
+// listReports prints the reports defined in the config
+func listReports() error {
+	reports := viper.GetStringMap("reports")
+	if len(reports) == 0 {
+		ui.PrintInfo("No reports defined. Add them under reports: in ~/.taskman.yaml.")
+		return nil
+	}
+
+	names := make([]string, 0, len(reports))
+	for name := range reports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		desc := viper.GetString("reports." + name + ".description")
+		if filter := viper.GetString("reports." + name + ".filter"); filter != "" {
+			desc = strings.TrimSpace(desc + " " + ui.CyanText.Sprintf("[%s]", filter))
+		}
+		fmt.Printf("  %-16s %s\n", name, desc)
+	}
+	return nil
+}
+
+// runReport lists the tasks matching the filter of the report and the
+// arguments, using the report's settings for flags that are not given
+func runReport(cmd *cobra.Command, name string, args []string) error {
+	store, err := openStore()
+	if err != nil {
+		return err
+	}
+
+	q, err := parseFilters(viper.GetString("reports."+name+".filter"), strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+
+	page, err := selectTasks(cmd, name, store, q)
+	if err != nil {
+		return err
+	}
+
+	if listFormat != "" {
+		return renderTasks(page.tasks)
+	}
+	if structuredOutput() {
+		res := output.NewResult(cmd.Name())
+		for _, t := range page.tasks {
+			res.AddTask(t)
+		}
+		return writeResult(res)
+	}
+
+	showContext()
+	if len(page.tasks) == 0 {
+		if page.matched > 0 {
+			ui.PrintInfo(fmt.Sprintf("No tasks past offset %d; %d task(s) match the filters.", page.opts.offset, page.matched))
+			return nil
+		}
+		ui.PrintInfo("No tasks found matching the filters.")
+		return nil
+	}
+
+	return withPager(!listNoPager, func() error {
+		displayPage(page)
+		return nil
+	})
+}
+
+// displayPage prints the task table of a page, followed by its summary
+func displayPage(page reportPage) {
+	if listTree {
+		ui.DisplayTasksTree(page.tasks, page.all, page.opts.table)
+	} else {
+		ui.DisplayTasksTable(page.tasks, page.opts.table)
+	}
+	if len(page.tasks) < page.matched {
+		fmt.Fprintf(ui.Out, "Showing %d-%d of %d tasks\n", page.opts.offset+1, page.opts.offset+len(page.tasks), page.matched)
+	}
+	showSummary(page.tasks)
+}
+
+// reportPage is a page of the tasks of a report
+type reportPage struct {
+	tasks   []*task.Task // the tasks on the page, sorted
+	all     []*task.Task // all tasks in the store
+	matched int          // the number of tasks matching the filters
+	opts    reportOptions
+}
+
+// selectTasks returns the page of tasks matching the query and the list flags,
+// sorted and paginated with the settings of the report
+func selectTasks(cmd *cobra.Command, name string, store task.Store, q *query.Query) (reportPage, error) {
+	var page reportPage
+	tasks, err := store.GetAll()
+	if err != nil {
+		return page, err
+	}
+	filteredTasks, err := filterTasks(q.Filter(tasks))
+	if err != nil {
+		return page, err
+	}
+
+	opts, err := listOptions(cmd, name)
+	if err != nil {
+		return page, err
+	}
+	urgency, err := urgencyScores(tasks)
+	if err != nil {
+		return page, err
+	}
+	opts.table.Urgency = urgency
+	task.SortTasks(filteredTasks, opts.sort, urgency)
+
+	page = reportPage{all: tasks, matched: len(filteredTasks), opts: opts}
+	page.tasks = paginate(filteredTasks, opts.offset, opts.limit)
+	return page, nil
+}
+
+// reportOptions are the sort order, page and columns of a task report
+type reportOptions struct {
+	sort   []task.SortKey
+	limit  int
+	offset int
+	table  ui.TableOptions
+}
+
+// listOptions reads the --sort, --columns, --limit and --offset flags. Flags
+// that are not given fall back to the settings of the report in the config:
+//
+//	reports:
+//	  list:
+//	    sort: urgency-,due+
+//	    columns: id,priority,desc,due
+//	    limit: 25
+func listOptions(cmd *cobra.Command, report string) (reportOptions, error) {
+	setting := func(flag string) string {
+		key := "reports." + report + "." + flag
+		if !cmd.Flags().Changed(flag) && viper.IsSet(key) {
+			return viper.GetString(key)
+		}
+		return cmd.Flags().Lookup(flag).Value.String()
+	}
+
+	var opts reportOptions
+	var err error
+	if opts.sort, err = task.ParseSort(setting("sort")); err != nil {
+		return opts, err
+	}
+	if opts.table.Columns, err = ui.ParseColumns(setting("columns")); err != nil {
+		return opts, err
+	}
+	if opts.limit, err = strconv.Atoi(setting("limit")); err != nil || opts.limit < 0 {
+		return opts, fmt.Errorf("invalid limit: %s", setting("limit"))
+	}
+	if opts.offset, err = strconv.Atoi(setting("offset")); err != nil || opts.offset < 0 {
+		return opts, fmt.Errorf("invalid offset: %s", setting("offset"))
+	}
+	return opts, nil
+}
+
+// paginate returns at most limit tasks after skipping offset, or all remaining
+// tasks for a limit of 0
+func paginate(tasks []*task.Task, offset, limit int) []*task.Task {
+	if offset >= len(tasks) {
+		return nil
+	}
+	tasks = tasks[offset:]
+	if limit > 0 && limit < len(tasks) {
+		tasks = tasks[:limit]
+	}
+	return tasks
+}
+
+// reportArgs rewrites 'taskman <name> ...' to 'taskman report <name> ...' when
+// name is not a command, so that reports can be run directly
+func reportArgs(args []string) []string {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || strings.HasPrefix(args[0], "__") {
+		return args
+	}
+
+	rootCmd.InitDefaultHelpCmd()
+	rootCmd.InitDefaultCompletionCmd()
+	if c, _, err := rootCmd.Find(args); err == nil && c != rootCmd {
+		return args
+	}
+	return append([]string{reportCmd.Name()}, args...)
+}
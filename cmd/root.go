@@ -26,10 +26,12 @@ Example usage:
   taskman list
   taskman complete 1
   taskman delete 2`,
+	PersistentPreRunE: setupOutput,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
+	rootCmd.SetArgs(reportArgs(os.Args[1:]))
 	err := rootCmd.Execute()
 	if err != nil {
 		os.Exit(1)
@@ -42,6 +44,7 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.taskman.yaml)")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "", "machine-readable output format (json, yaml, csv, ndjson)")
 
 	// Bind flags to viper
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
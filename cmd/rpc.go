@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/rpc"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var rpcCmd = &cobra.Command{
+	Use:   "rpc",
+	Short: "Speak JSON-RPC 2.0 on stdin and stdout for editor integrations",
+	Long: `Answer line-delimited JSON-RPC 2.0 requests on stdin, one JSON object per line,
+until stdin is closed. Editor plugins start it once and keep it running.
+
+Methods, with named parameters:
+
+  add          {description, priority, status, project, tags, due, recur,
+               parent_id, depends_on}
+  getAll       {filter}
+  getById      {id}
+  update       {id, and the fields of add to change}
+  delete       {id}
+  complete     {id}
+  addTag       {id, tag}
+  removeTag    {id, tag}
+  subscribe    {}; then tasksChanged notifications arrive whenever the tasks
+               change, also through other taskman commands
+  unsubscribe  {subscription}
+
+Tasks use the field names of the JSON store. Errors have a code: the JSON-RPC
+codes -32700 to -32603, and -32001 for a missing task, -32002 for an invalid
+task and -32003 for an invalid filter. The context does not apply.`,
+	Example: `  echo '{"jsonrpc": "2.0", "id": 1, "method": "getAll", "params": {"filter": "+OPEN"}}' | taskman rpc
+  echo '{"jsonrpc": "2.0", "id": 2, "method": "add", "params": {"description": "Fix bug", "tags": ["work"]}}' | taskman rpc`,
+	Args: cobra.NoArgs,
+	RunE: serveRPC,
+}
+
+func init() {
+	rootCmd.AddCommand(rpcCmd)
+}
+
+func serveRPC(cmd *cobra.Command, args []string) error {
+	// stdout carries the protocol only
+	ui.Messages = os.Stderr
+	color.NoColor = true
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+	path, err := storagePath()
+	if err != nil {
+		return err
+	}
+
+	server := rpc.New(store, rpc.Options{Validate: validateTask, WatchPath: path, Log: os.Stderr})
+	return server.Serve(os.Stdin, os.Stdout)
+}
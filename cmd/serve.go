@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/vkhangstack/taskman/internal/server"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve tasks over a local JSON REST API",
+	Long: `Serve the task store as a JSON REST API, for dashboards and editor plugins:
+
+  GET    /tasks                  list tasks; ?filter=, ?sort=, ?limit=, ?offset=
+  POST   /tasks                  create a task
+  GET    /tasks/{id}             get a task
+  PATCH  /tasks/{id}             change the fields given in the body
+  DELETE /tasks/{id}             delete a task
+  POST   /tasks/{id}/complete    complete a task
+  POST   /tasks/{id}/tags        add the tag {"tag": "..."}
+  DELETE /tasks/{id}/tags/{tag}  remove a tag
+
+Task bodies use the field names of the JSON store: description, priority,
+status, project, tags, due (a date such as 2026-03-01 or fri), recur,
+parent_id and depends_on. Responses with a single task carry an ETag; send it
+back in If-Match to only change the task if nobody else has since.
+
+The context does not apply to the API. To require a bearer token, set it in
+~/.taskman.yaml:
+
+  serve:
+    token: s3cret`,
+	Example: `  taskman serve
+  taskman serve --addr 127.0.0.1:8080
+  curl -s -G localhost:7070/tasks --data-urlencode 'filter=+OPEN'
+  curl -s -X POST localhost:7070/tasks -d '{"description": "Call Ann", "due": "fri"}'`,
+	Args: cobra.NoArgs,
+	RunE: serveAPI,
+}
+
+var serveAddr string
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:7070", "Address to listen on")
+}
+
+func serveAPI(cmd *cobra.Command, args []string) error {
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	token := viper.GetString("serve.token")
+	if host, _, err := net.SplitHostPort(serveAddr); err == nil && token == "" {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			ui.PrintWarning(fmt.Sprintf("Serving on %s without a token; anyone who can reach it can change tasks", serveAddr))
+		}
+	}
+
+	listener, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", serveAddr, err)
+	}
+	srv := &http.Server{
+		Handler: server.New(store, server.Options{
+			Token:    token,
+			Validate: validateTask,
+			Urgency:  urgencyScores,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	ui.PrintInfo(fmt.Sprintf("Serving tasks on http://%s (Ctrl-C to stop)", listener.Addr()))
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/output"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show [task ID...]",
+	Short: "Show task details",
+	Long: `Show all details of one or more tasks, including their age, how long they took
+to complete and a timeline of their status changes.`,
+	Example: `  taskman show 1
+  taskman show 2 3`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: showTasks,
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+}
+
+func showTasks(cmd *cobra.Command, args []string) error {
+	var ids []int
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid task ID: %s", arg)
+		}
+		ids = append(ids, id)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	res := output.NewResult("show")
+	for _, id := range ids {
+		t, err := store.GetByID(id)
+		if err != nil {
+			reportError(res, id, fmt.Errorf("failed to retrieve task with ID %d: %w", id, err))
+			continue
+		}
+		res.AddTask(t)
+		if !structuredOutput() {
+			ui.DisplayTaskDetails(t)
+		}
+	}
+
+	return finishResult(cmd, res)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status [task ID] [status]",
+	Short: "Change the status of a task",
+	Long: `Move a task to another status. Valid statuses are: todo, pending, in_progress,
+completed, archived and deleted. Setting a task to deleted keeps it in the list; use
+'taskman delete' to remove it permanently.`,
+	Example: `  taskman status 1 in_progress
+  taskman status 2 archived`,
+	Args: cobra.ExactArgs(2),
+	RunE: changeStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
+
+func changeStatus(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid task ID: %s", args[0])
+	}
+
+	status := args[1]
+	if !isValidStatus(status) {
+		return fmt.Errorf("invalid status: %s. Valid statuses are: todo, pending, in_progress, completed, archived, deleted", status)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	if err := store.MarkStatus(id, status); err != nil {
+		return fmt.Errorf("failed to update task with ID %d: %w", id, err)
+	}
+
+	if structuredOutput() {
+		return writeTaskResult("status", store, id)
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Task %d is now %s", id, ui.FormatStatus(status)))
+	return nil
+}
+
+func isValidStatus(s string) bool {
+	validStatuses := []string{
+		task.StatusTodo,
+		task.StatusPending,
+		task.StatusInProgress,
+		task.StatusCompleted,
+		task.StatusArchived,
+		task.StatusDeleted,
+	}
+	for _, valid := range validStatuses {
+		if s == valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+const (
+	driverJSON   = "json"
+	driverSQLite = "sqlite"
+)
+
+// openStore opens the task store selected by the storage.driver config key
+func openStore() (task.Store, error) {
+	path, err := storagePath()
+	if err != nil {
+		return nil, err
+	}
+
+	switch driver := storageDriver(); driver {
+	case driverJSON:
+		return task.NewFileStoreAt(path)
+	case driverSQLite:
+		return task.NewSQLiteStore(path)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s. Valid drivers are: json, sqlite", driver)
+	}
+}
+
+// storageDriver returns the configured storage driver, defaulting to json
+func storageDriver() string {
+	if driver := viper.GetString("storage.driver"); driver != "" {
+		return driver
+	}
+	return driverJSON
+}
+
+// storagePath returns storage.path, or the default file for the driver
+// inside ~/.taskman
+func storagePath() (string, error) {
+	if path := viper.GetString("storage.path"); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	name := "tasks.json"
+	if storageDriver() == driverSQLite {
+		name = "tasks.db"
+	}
+	return filepath.Join(home, ".taskman", name), nil
+}
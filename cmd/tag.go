@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Add or remove task tags",
+	Long:  `Add tags to or remove tags from an existing task.`,
+	Example: `  taskman tag add 1 work
+  taskman tag add 1 work urgent
+  taskman tag remove 1 urgent`,
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add [task ID] [tag...]",
+	Short: "Add tags to a task",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  addTags,
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:     "remove [task ID] [tag...]",
+	Aliases: []string{"rm"},
+	Short:   "Remove tags from a task",
+	Args:    cobra.MinimumNArgs(2),
+	RunE:    removeTags,
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRemoveCmd)
+}
+
+func addTags(cmd *cobra.Command, args []string) error {
+	id, tags, err := parseTagArgs(args)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	for _, tag := range tags {
+		if err := store.AddTag(id, tag); err != nil {
+			return fmt.Errorf("failed to add tag %s to task with ID %d: %w", tag, id, err)
+		}
+	}
+
+	if structuredOutput() {
+		return writeTaskResult("tag add", store, id)
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Tagged task %d with %s", id, ui.FormatTags(tags)))
+	return nil
+}
+
+func removeTags(cmd *cobra.Command, args []string) error {
+	id, tags, err := parseTagArgs(args)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	for _, tag := range tags {
+		if !store.HasTag(id, tag) {
+			ui.PrintWarning(fmt.Sprintf("Task %d does not have tag %s", id, tag))
+			continue
+		}
+		if err := store.RemoveTag(id, tag); err != nil {
+			return fmt.Errorf("failed to remove tag %s from task with ID %d: %w", tag, id, err)
+		}
+	}
+
+	if structuredOutput() {
+		return writeTaskResult("tag remove", store, id)
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Updated tags of task %d", id))
+	return nil
+}
+
+// parseTagArgs splits "<id> <tag>..." arguments into a task ID and trimmed tags
+func parseTagArgs(args []string) (int, []string, error) {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid task ID: %s", args[0])
+	}
+
+	var tags []string
+	for _, arg := range args[1:] {
+		tag := strings.TrimSpace(arg)
+		if tag == "" {
+			return 0, nil, fmt.Errorf("tag cannot be empty")
+		}
+		tags = append(tags, tag)
+	}
+
+	return id, tags, nil
+}
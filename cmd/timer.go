@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/dates"
+	"github.com/vkhangstack/taskman/internal/output"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var startCmd = &cobra.Command{
+	Use:   "start [task ID]",
+	Short: "Start tracking time on a task",
+	Long: `Start the timer of a task and move it to in_progress. Only one timer runs at a
+time: starting a task stops the timer of the task you were working on.`,
+	Example: `  taskman start 3
+  taskman stop`,
+	Args: cobra.ExactArgs(1),
+	RunE: startTimer,
+}
+
+var stopCmd = &cobra.Command{
+	Use:   "stop [task ID]",
+	Short: "Stop tracking time",
+	Long:  `Stop the running timer. The task stays in_progress; complete it with 'taskman complete'.`,
+	Example: `  taskman stop
+  taskman stop 3`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: stopTimer,
+}
+
+var logCmd = &cobra.Command{
+	Use:   "log [task ID] [duration] [note...]",
+	Short: "Log time spent on a task",
+	Long: `Record time spent on a task without running a timer. The duration uses Go
+notation such as 45m, 1h30m or 2h. The entry ends now, or at the time given with --at.`,
+	Example: `  taskman log 3 1h30m "Code review"
+  taskman log 3 45m --at "2026-03-02 17:00"
+  taskman log 4 2h "Workshop" --at yesterday`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: logTime,
+}
+
+var logAt string
+
+func init() {
+	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(stopCmd)
+	rootCmd.AddCommand(logCmd)
+
+	logCmd.Flags().StringVar(&logAt, "at", "", "When the work ended (default now)")
+}
+
+func startTimer(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid task ID: %s", args[0])
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	t, err := store.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve task with ID %d: %w", id, err)
+	}
+	blockers, err := blockedBy(store, t)
+	if err != nil {
+		return err
+	}
+	if len(blockers) > 0 {
+		return fmt.Errorf("task %d is blocked by unfinished tasks %s", id, formatIDs(blockers))
+	}
+
+	stopped, err := store.StartTimer(id)
+	if err != nil {
+		return fmt.Errorf("failed to start timer of task with ID %d: %w", id, err)
+	}
+
+	res := output.NewResult("start")
+	if stopped != 0 {
+		if s, err := store.GetByID(stopped); err == nil {
+			res.AddTask(s)
+			if !structuredOutput() {
+				ui.PrintInfo(fmt.Sprintf("Stopped timer of task %d (%s in total)", stopped, ui.FormatSpan(s.TimeSpent(time.Now()))))
+			}
+		}
+	}
+	if started, err := store.GetByID(id); err == nil {
+		res.AddTask(started)
+	}
+
+	if structuredOutput() {
+		return writeResult(res)
+	}
+	ui.PrintSuccess(fmt.Sprintf("Started timer of task %d: %s", id, t.Description))
+	return nil
+}
+
+func stopTimer(cmd *cobra.Command, args []string) error {
+	id := 0
+	if len(args) == 1 {
+		var err error
+		if id, err = strconv.Atoi(args[0]); err != nil {
+			return fmt.Errorf("invalid task ID: %s", args[0])
+		}
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	stopped, err := store.StopTimer(id)
+	if err != nil {
+		return fmt.Errorf("failed to stop timer: %w", err)
+	}
+
+	if structuredOutput() {
+		return writeTaskResult("stop", store, stopped)
+	}
+
+	t, err := store.GetByID(stopped)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve task with ID %d: %w", stopped, err)
+	}
+	last := t.TimeLog[len(t.TimeLog)-1]
+	ui.PrintSuccess(fmt.Sprintf("Stopped timer of task %d after %s (%s in total)",
+		stopped, ui.FormatSpan(last.Duration(time.Now())), ui.FormatSpan(t.TimeSpent(time.Now()))))
+	return nil
+}
+
+func logTime(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid task ID: %s", args[0])
+	}
+	d, err := time.ParseDuration(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid duration: %s (try 45m, 1h30m or 2h)", args[1])
+	}
+	note := strings.TrimSpace(strings.Join(args[2:], " "))
+
+	end := time.Now()
+	if logAt != "" {
+		if end, err = dates.Parse(logAt, end); err != nil {
+			return err
+		}
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	t, err := store.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve task with ID %d: %w", id, err)
+	}
+	if err := t.LogTime(d, end, note); err != nil {
+		return err
+	}
+	if err := store.Update(t); err != nil {
+		return fmt.Errorf("failed to update task with ID %d: %w", id, err)
+	}
+
+	if structuredOutput() {
+		return writeTaskResult("log", store, id)
+	}
+	ui.PrintSuccess(fmt.Sprintf("Logged %s on task %d (%s in total)", ui.FormatSpan(d), id, ui.FormatSpan(t.TimeSpent(time.Now()))))
+	return nil
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/dates"
+	"github.com/vkhangstack/taskman/internal/output"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/ui"
+)
+
+var timesheetCmd = &cobra.Command{
+	Use:   "timesheet",
+	Short: "Show the time tracked per day",
+	Long: `Show the time tracked per task, tag or project for each day of a period. The
+period defaults to the current week, Monday to Sunday. Time entries crossing
+midnight are split between the days, and a running timer counts up to now.
+
+With --by tag, a task with several tags counts towards each of them, so the
+rows can add up to more than the total. Time on untagged tasks or tasks
+without a project is shown as "(none)".
+
+The table rounds to minutes. For billing, export the exact time entries with
+--output csv, json, yaml or ndjson: every entry is listed with its start, end
+and duration in seconds, clipped to the period.`,
+	Example: `  taskman timesheet
+  taskman timesheet --by project
+  taskman timesheet --from 2026-03-01 --to 2026-03-31 --by tag
+  taskman timesheet --from -6d -o csv > week.csv`,
+	RunE: showTimesheet,
+}
+
+var (
+	timesheetWeek bool
+	timesheetFrom string
+	timesheetTo   string
+	timesheetBy   string
+)
+
+func init() {
+	rootCmd.AddCommand(timesheetCmd)
+
+	timesheetCmd.Flags().BoolVar(&timesheetWeek, "week", false, "Report the current week, Monday to Sunday (default)")
+	timesheetCmd.Flags().StringVar(&timesheetFrom, "from", "", "First day of the period")
+	timesheetCmd.Flags().StringVar(&timesheetTo, "to", "", "Last day of the period (default today)")
+	timesheetCmd.Flags().StringVar(&timesheetBy, "by", "task", "Group time by task, tag or project")
+}
+
+func showTimesheet(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	from, to, err := timesheetPeriod(now)
+	if err != nil {
+		return err
+	}
+
+	var group func(t *task.Task) []string
+	switch timesheetBy {
+	case "task":
+		group = func(t *task.Task) []string { return []string{fmt.Sprintf("%d %s", t.ID, t.Description)} }
+	case "tag":
+		group = func(t *task.Task) []string {
+			if len(t.Tags) == 0 {
+				return []string{"(none)"}
+			}
+			return t.Tags
+		}
+	case "project":
+		group = func(t *task.Task) []string {
+			if t.Project == "" {
+				return []string{"(none)"}
+			}
+			return []string{t.Project}
+		}
+	default:
+		return fmt.Errorf("invalid grouping: %s. Valid values are: task, tag, project", timesheetBy)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize task store: %w", err)
+	}
+
+	q, err := parseFilter(nil)
+	if err != nil {
+		return err
+	}
+	tasks, err := store.GetAll()
+	if err != nil {
+		return err
+	}
+	slices := task.TimeSlices(q.Filter(tasks), from, to, now)
+
+	if structuredOutput() {
+		return output.WriteTimesheet(os.Stdout, outputFormat, output.NewTimesheet(from, to, slices))
+	}
+
+	showContext()
+	if len(slices) == 0 {
+		ui.PrintInfo(fmt.Sprintf("No time tracked between %s and %s.",
+			from.Format("02/01/2006"), to.AddDate(0, 0, -1).Format("02/01/2006")))
+		return nil
+	}
+
+	var days []time.Time
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	dayIndex := func(t time.Time) int {
+		for i := len(days) - 1; i >= 0; i-- {
+			if !t.Before(days[i]) {
+				return i
+			}
+		}
+		return 0
+	}
+
+	rows := make(map[string]*ui.TimesheetRow)
+	var labels []string
+	total := ui.TimesheetRow{Days: make([]time.Duration, len(days))}
+	for _, s := range slices {
+		for _, part := range s.SplitDays() {
+			day, d := dayIndex(part.Start), part.Duration()
+			total.Days[day] += d
+			total.Total += d
+			for _, label := range group(s.Task) {
+				row, ok := rows[label]
+				if !ok {
+					row = &ui.TimesheetRow{Label: label, Days: make([]time.Duration, len(days))}
+					rows[label] = row
+					labels = append(labels, label)
+				}
+				row.Days[day] += d
+				row.Total += d
+			}
+		}
+	}
+
+	if timesheetBy != "task" {
+		sort.Strings(labels)
+	}
+	var ordered []ui.TimesheetRow
+	for _, label := range labels {
+		ordered = append(ordered, *rows[label])
+	}
+
+	fmt.Printf("\nTimesheet %s – %s\n\n", from.Format("02/01/2006"), to.AddDate(0, 0, -1).Format("02/01/2006"))
+	heading := map[string]string{"task": "Task", "tag": "Tag", "project": "Project"}[timesheetBy]
+	ui.DisplayTimesheet(heading, days, ordered, total)
+	return nil
+}
+
+// timesheetPeriod returns the start of the first day and the end of the last
+// day of the period requested by the flags
+func timesheetPeriod(now time.Time) (time.Time, time.Time, error) {
+	if timesheetWeek && (timesheetFrom != "" || timesheetTo != "") {
+		return time.Time{}, time.Time{}, fmt.Errorf("--week cannot be combined with --from or --to")
+	}
+
+	if timesheetFrom == "" && timesheetTo == "" {
+		// Weeks start on Monday
+		monday := dates.StartOfDay(now).AddDate(0, 0, -(int(now.Weekday())+6)%7)
+		return monday, monday.AddDate(0, 0, 7), nil
+	}
+
+	from, to := dates.StartOfDay(now), dates.StartOfDay(now).AddDate(0, 0, 1)
+	if timesheetTo != "" {
+		t, err := dates.Parse(timesheetTo, now)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = dates.StartOfDay(t).AddDate(0, 0, 1)
+	}
+	if timesheetFrom != "" {
+		t, err := dates.Parse(timesheetFrom, now)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = dates.StartOfDay(t)
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--from must not be after --to")
+	}
+	return from, to, nil
+}
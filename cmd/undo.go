@@ -3,72 +3,115 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/vkhangstack/taskman/internal/output"
 	"github.com/vkhangstack/taskman/internal/task"
 	"github.com/vkhangstack/taskman/internal/ui"
 	"strconv"
-	"time"
 )
 
 var undoCmd = &cobra.Command{
-	Use:   "undo [task ID]",
-	Short: "Undo the last action on a task",
-	Long:  `Undo the last action performed on a task, such as marking it as completed or deleting it. This will restore the task to its previous state.`,
-	Args:  cobra.MinimumNArgs(1),
-	Example: `  taskman undo 1
-	  taskman undo 2 3 
-	  taskman undo 3 4 5`,
+	Use:   "undo [task ID...]",
+	Short: "Undo the last operations",
+	Long: `Undo the last operations recorded in the history, such as adding, updating,
+completing or deleting tasks. Deleted tasks are restored with their original ID.
+
+Without arguments the last operation is undone; use --count to undo several. With
+task IDs, the last operation on each of those tasks is undone. Use 'taskman history'
+to see what will be undone and 'taskman redo' to reapply an undone operation.
+
+The history is only recorded by the json storage driver; with storage.driver: sqlite,
+undo, redo and history are not available.`,
+	Example: `  taskman undo
+  taskman undo --count 3
+  taskman undo 1
+  taskman undo 2 3`,
 	RunE: undoTask,
 }
 
+var (
+	undoCount int
+	undoForce bool
+)
+
 func init() {
 	rootCmd.AddCommand(undoCmd)
+
+	undoCmd.Flags().IntVarP(&undoCount, "count", "n", 1, "Number of operations to undo")
+	undoCmd.Flags().BoolVarP(&undoForce, "force", "f", false, "Undo even if the tasks were modified since")
 }
 
 func undoTask(cmd *cobra.Command, args []string) error {
-	store, err := task.NewFileStore()
+	history, err := openHistory()
 	if err != nil {
 		return err
 	}
 
-	var errors []error
-	for _, arg := range args {
-		id, err := strconv.Atoi(arg)
+	if len(args) == 0 {
+		if undoCount < 1 {
+			return fmt.Errorf("invalid count: %d", undoCount)
+		}
+
+		entries, err := history.Undo(undoCount, undoForce)
 		if err != nil {
-			errors = append(errors, fmt.Errorf("invalid task ID: %s", arg))
-			continue
+			return fmt.Errorf("failed to undo: %w", err)
 		}
-		// Check if the task exists
-		taskRecord, err := store.GetByID(id)
+		return reportJournalEntries("undo", "Undid", entries)
+	}
+
+	res := output.NewResult("undo")
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
 		if err != nil {
-			errors = append(errors, fmt.Errorf("failed to retrieve task with ID %d: %w", id, err))
+			reportError(res, 0, fmt.Errorf("invalid task ID: %s", arg))
 			continue
 		}
 
-		if taskRecord.Status == task.StatusCompleted {
-			errors = append(errors, fmt.Errorf("task with ID %d is already completed and cannot be undone", id))
+		entry, err := history.UndoTask(id, undoForce)
+		if err != nil {
+			reportError(res, id, fmt.Errorf("failed to undo action for task with ID %d: %w", id, err))
 			continue
 		}
-		if taskRecord.Status == task.StatusDeleted {
-			errors = append(errors, fmt.Errorf("task with ID %d is already deleted and cannot be undone", id))
-			continue
+		addJournalTasks(res, entry)
+		if !structuredOutput() {
+			printJournalEntry("Undid", entry)
 		}
-		taskRecord.Status = task.StatusPending // Reset status to pending
-		taskRecord.CompletedAt = nil           // Clear completed timestamp
-		taskRecord.UpdatedAt = time.Now()
+	}
 
-		if err := store.Update(taskRecord); err != nil {
-			errors = append(errors, fmt.Errorf("failed to undo action for task with ID %d: %w", id, err))
-		}
+	return finishResult(cmd, res)
+}
+
+// openHistory opens the task store and checks that it records history
+func openHistory() (task.HistoryStore, error) {
+	store, err := openStore()
+	if err != nil {
+		return nil, err
 	}
 
-	if len(errors) > 0 {
-		for _, err := range errors {
-			ui.PrintError(err.Error())
-		}
-		return nil
+	history, ok := store.(task.HistoryStore)
+	if !ok {
+		return nil, fmt.Errorf("the %s storage driver does not record history; undo, redo and history need storage.driver: json", storageDriver())
 	}
+	return history, nil
+}
 
-	ui.PrintSuccess("Tasks undone successfully!")
+// reportJournalEntries prints the entries made by undo or redo, or writes the
+// tasks they left behind in structured output mode
+func reportJournalEntries(command, verb string, entries []*task.JournalEntry) error {
+	if structuredOutput() {
+		res := output.NewResult(command)
+		addJournalTasks(res, entries...)
+		return writeResult(res)
+	}
+	for _, e := range entries {
+		printJournalEntry(verb, e)
+	}
 	return nil
+}
 
+// printJournalEntry prints an undo or redo entry and the changes it made
+func printJournalEntry(verb string, e *task.JournalEntry) {
+	ui.PrintSuccess(fmt.Sprintf("%s operation %d", verb, e.Ref))
+	for _, c := range e.Changes {
+		fmt.Printf("  %s %s\n", ui.FormatID(c.TaskID), c.Summary())
+	}
 }
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/ui"
+	"github.com/vkhangstack/taskman/internal/watch"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [filter]",
+	Short: "Show the task list and redraw it whenever the tasks change",
+	Long: `Show the list table and redraw it whenever the task store changes, whether
+through taskman, the REST API or a sync. Keep it open in a terminal pane to
+follow a shared board.
+
+watch takes the filter expressions, --sort, --columns, --limit, --offset and
+--tree of list, and the settings under reports: list: in ~/.taskman.yaml.
+Rows of tasks that changed since the previous redraw are marked with ».
+Press Ctrl-C to stop.`,
+	Example: `  taskman watch
+  taskman watch +OPEN --sort urgency
+  taskman watch 'project:team and status:todo,in_progress' --columns id,status,desc,due`,
+	RunE: watchTasks,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	addReportFlags(watchCmd)
+	watchCmd.Flags().MarkHidden("no-pager")
+}
+
+func watchTasks(cmd *cobra.Command, args []string) error {
+	if structuredOutput() {
+		return fmt.Errorf("watch cannot be combined with --output")
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return err
+	}
+	q, err := parseFilters(viper.GetString("reports.list.filter"), strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	// Check the flags once, so that mistakes stop watch before it clears the screen
+	if _, err := listOptions(cmd, "list"); err != nil {
+		return err
+	}
+
+	path, err := storagePath()
+	if err != nil {
+		return err
+	}
+	watcher, err := watch.New(path, watch.DefaultDelay)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var seen map[int]string
+	redraw := func() {
+		page, err := selectTasks(cmd, "list", store, q)
+		clearScreen()
+		ui.PrintInfo(fmt.Sprintf("Watching %s, updated %s (Ctrl-C to stop)", path, time.Now().Format("15:04:05")))
+		if err != nil {
+			// Keep the previous fingerprints, so that the next redraw marks the changes since then
+			ui.PrintError(err.Error())
+			return
+		}
+
+		page.opts.table.Marked, seen = changedTasks(page.all, seen)
+		showContext()
+		if len(page.tasks) == 0 {
+			ui.PrintInfo("No tasks found matching the filters.")
+			return
+		}
+		displayPage(page)
+	}
+
+	redraw()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-watcher.C:
+			if !ok {
+				return nil
+			}
+			redraw()
+		case err, ok := <-watcher.Errors:
+			if ok {
+				ui.PrintWarning(fmt.Sprintf("Watch error: %v", err))
+			}
+		}
+	}
+}
+
+// changedTasks returns the IDs of the tasks that are new or differ from the
+// fingerprints in seen, and the fingerprints of the tasks. Nothing counts as
+// changed on the first call, when seen is nil.
+func changedTasks(tasks []*task.Task, seen map[int]string) (map[int]bool, map[int]string) {
+	changed := make(map[int]bool)
+	current := make(map[int]string, len(tasks))
+	for _, t := range tasks {
+		data, _ := json.Marshal(t)
+		sum := sha256.Sum256(data)
+		current[t.ID] = string(sum[:])
+		if seen != nil && seen[t.ID] != current[t.ID] {
+			changed[t.ID] = true
+		}
+	}
+	return changed, current
+}
+
+// clearScreen clears the terminal and moves the cursor to the top left
+func clearScreen() {
+	fmt.Fprint(ui.Out, "\033[H\033[2J")
+}
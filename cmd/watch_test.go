@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+func TestChangedTasks(t *testing.T) {
+	tasks := []*task.Task{
+		{ID: 1, Description: "One", Status: task.StatusTodo},
+		{ID: 2, Description: "Two", Status: task.StatusTodo},
+	}
+
+	changed, seen := changedTasks(tasks, nil)
+	if len(changed) != 0 {
+		t.Errorf("first call marks %v, want nothing", changed)
+	}
+	if len(seen) != 2 {
+		t.Fatalf("seen has %d tasks, want 2", len(seen))
+	}
+
+	changed, seen = changedTasks(tasks, seen)
+	if len(changed) != 0 {
+		t.Errorf("unchanged tasks marked %v", changed)
+	}
+
+	// Task 2 changes, task 3 is new and task 1 stays the same
+	next := []*task.Task{
+		{ID: 1, Description: "One", Status: task.StatusTodo},
+		{ID: 2, Description: "Two", Status: task.StatusCompleted},
+		{ID: 3, Description: "Three", Status: task.StatusTodo},
+	}
+	changed, seen = changedTasks(next, seen)
+	if want := map[int]bool{2: true, 3: true}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+
+	// A removed task is not marked, and is forgotten
+	changed, seen = changedTasks(next[:1], seen)
+	if len(changed) != 0 || len(seen) != 1 {
+		t.Errorf("after removing tasks: changed = %v, %d seen", changed, len(seen))
+	}
+}
@@ -0,0 +1,149 @@
+// Package dates parses the absolute and relative date expressions accepted by
+// taskman flags, such as "2026-03-01", "tomorrow", "fri", "+3d", "eow" or "eom".
+package dates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// absoluteLayouts are the absolute formats accepted by Parse. Layouts without
+// a time of day resolve to the end of that day.
+var absoluteLayouts = []struct {
+	layout  string
+	dayOnly bool
+}{
+	{time.RFC3339, false},
+	{"2006-01-02T15:04", false},
+	{"2006-01-02 15:04", false},
+	{"2006-01-02", true},
+	{"02/01/2006 15:04", false},
+	{"02/01/2006", true},
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// Parse resolves a date expression relative to now. Named days, weekdays and
+// end-of-period keywords resolve to the end of the day (23:59:59) so that a task
+// due "today" only becomes overdue once the day is over.
+//
+// Supported forms:
+//
+//	2026-03-01, 2026-03-01 15:04, 01/03/2026, RFC 3339
+//	now, today, tomorrow, yesterday
+//	mon..sun, monday..sunday   the next such day after today
+//	eod, eow, eom, eoy         end of day, week (Sunday), month or year
+//	+3d, -2w, 1m, +12h, +1y    offsets in hours, days, weeks, months or years
+func Parse(s string, now time.Time) (time.Time, error) {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+
+	// Layouts such as RFC 3339 need the upper-case T and Z kept
+	for _, l := range absoluteLayouts {
+		if t, err := time.ParseInLocation(l.layout, trimmed, now.Location()); err == nil {
+			if l.dayOnly {
+				return EndOfDay(t), nil
+			}
+			return t, nil
+		}
+	}
+
+	expr := strings.ToLower(trimmed)
+	switch expr {
+	case "now":
+		return now, nil
+	case "today", "eod":
+		return EndOfDay(now), nil
+	case "tomorrow":
+		return EndOfDay(now.AddDate(0, 0, 1)), nil
+	case "yesterday":
+		return EndOfDay(now.AddDate(0, 0, -1)), nil
+	case "eow":
+		// Weeks end on Sunday
+		days := (7 - int(now.Weekday())) % 7
+		return EndOfDay(now.AddDate(0, 0, days)), nil
+	case "eom":
+		firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return EndOfDay(firstOfMonth.AddDate(0, 1, -1)), nil
+	case "eoy":
+		return EndOfDay(time.Date(now.Year(), time.December, 31, 0, 0, 0, 0, now.Location())), nil
+	}
+
+	if wd, ok := weekdays[expr]; ok {
+		days := (int(wd) - int(now.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return EndOfDay(now.AddDate(0, 0, days)), nil
+	}
+
+	if t, ok := parseOffset(expr, now); ok {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date: %q (try 2026-01-31, tomorrow, fri, +3d, eow or eom)", s)
+}
+
+// parseOffset handles offsets such as "+3d", "-2w" or "12h"
+func parseOffset(expr string, now time.Time) (time.Time, bool) {
+	sign := 1
+	switch expr[0] {
+	case '+':
+		expr = expr[1:]
+	case '-':
+		sign = -1
+		expr = expr[1:]
+	}
+	if len(expr) < 2 {
+		return time.Time{}, false
+	}
+
+	n, err := strconv.Atoi(expr[:len(expr)-1])
+	if err != nil || n < 0 {
+		return time.Time{}, false
+	}
+	n *= sign
+
+	switch expr[len(expr)-1] {
+	case 'h':
+		return now.Add(time.Duration(n) * time.Hour), true
+	case 'd':
+		return EndOfDay(now.AddDate(0, 0, n)), true
+	case 'w':
+		return EndOfDay(now.AddDate(0, 0, 7*n)), true
+	case 'm':
+		return EndOfDay(now.AddDate(0, n, 0)), true
+	case 'y':
+		return EndOfDay(now.AddDate(n, 0, 0)), true
+	}
+	return time.Time{}, false
+}
+
+// StartOfDay returns midnight at the start of t's day
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// EndOfDay returns the last second of t's day
+func EndOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
+
+// SameDay reports whether a and b fall on the same calendar day
+func SameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.In(a.Location()).Date()
+	return ay == by && am == bm && ad == bd
+}
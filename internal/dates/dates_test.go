@@ -0,0 +1,95 @@
+package dates
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	loc := time.FixedZone("UTC+7", 7*60*60)
+	// A Wednesday
+	now := time.Date(2026, 3, 4, 10, 30, 0, 0, loc)
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 23, 59, 59, 0, loc)
+	}
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"2026-03-10", day(2026, 3, 10)},
+		{"10/03/2026", day(2026, 3, 10)},
+		{"2026-03-10 15:04", time.Date(2026, 3, 10, 15, 4, 0, 0, loc)},
+		{"2026-03-10T15:04", time.Date(2026, 3, 10, 15, 4, 0, 0, loc)},
+		{"10/03/2026 15:04", time.Date(2026, 3, 10, 15, 4, 0, 0, loc)},
+		{"2026-03-10T15:04:00Z", time.Date(2026, 3, 10, 15, 4, 0, 0, time.UTC)},
+		{"now", now},
+		{"today", day(2026, 3, 4)},
+		{"eod", day(2026, 3, 4)},
+		{"Tomorrow", day(2026, 3, 5)},
+		{" yesterday ", day(2026, 3, 3)},
+		{"thu", day(2026, 3, 5)},
+		{"friday", day(2026, 3, 6)},
+		{"sun", day(2026, 3, 8)},
+		{"mon", day(2026, 3, 9)},
+		{"wed", day(2026, 3, 11)}, // the next Wednesday, not today
+		{"eow", day(2026, 3, 8)},
+		{"eom", day(2026, 3, 31)},
+		{"eoy", day(2026, 12, 31)},
+		{"+3d", day(2026, 3, 7)},
+		{"3d", day(2026, 3, 7)},
+		{"-2d", day(2026, 3, 2)},
+		{"+2w", day(2026, 3, 18)},
+		{"+1m", day(2026, 4, 4)},
+		{"+1y", day(2027, 3, 4)},
+		{"+12h", time.Date(2026, 3, 4, 22, 30, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.expr, now)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Parse(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseEndOfWeekOnSunday(t *testing.T) {
+	sunday := time.Date(2026, 3, 8, 9, 0, 0, 0, time.UTC)
+	got, err := Parse("eow", sunday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := EndOfDay(sunday); !got.Equal(want) {
+		t.Errorf("eow on a Sunday = %v, want %v", got, want)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)
+	for _, expr := range []string{"", "  ", "someday", "+", "+d", "3", "+3x", "+-3d", "3.5d", "2026-02-30", "2026-13-01", "fr"} {
+		_, err := Parse(expr, now)
+		if err == nil {
+			t.Errorf("Parse(%q) succeeded", expr)
+			continue
+		}
+		if strings.TrimSpace(expr) != "" && !strings.Contains(err.Error(), "invalid date") {
+			t.Errorf("Parse(%q) error = %q, want an invalid date error", expr, err)
+		}
+	}
+}
+
+func TestSameDay(t *testing.T) {
+	loc := time.FixedZone("UTC+7", 7*60*60)
+	a := time.Date(2026, 3, 4, 1, 0, 0, 0, loc)
+	// 2026-03-03 20:00 UTC is already March 4th in UTC+7
+	if !SameDay(a, time.Date(2026, 3, 3, 20, 0, 0, 0, time.UTC)) {
+		t.Error("SameDay does not compare in the location of its first argument")
+	}
+	if SameDay(a, a.AddDate(0, 0, 1)) {
+		t.Error("SameDay is true for consecutive days")
+	}
+}
@@ -0,0 +1,504 @@
+// Package ical reads and writes tasks as iCalendar VTODO components
+// (RFC 5545), the to-dos of calendar clients:
+//
+//	BEGIN:VTODO
+//	UID:6f1c2d4e-8a3b-4c5d-9e6f-7a8b9c0d1e2f
+//	SUMMARY:Call the bank
+//	PRIORITY:1
+//	CATEGORIES:finance,phone
+//	STATUS:NEEDS-ACTION
+//	DUE:20260305T170000Z
+//	END:VTODO
+//
+// Fields map onto tasks as follows:
+//
+//	UID                   UUID, kept so that tasks can be synced back
+//	SUMMARY               Description
+//	PRIORITY 1-4, 5, 6-9  high, medium, low; 0 or none is medium
+//	CATEGORIES            tags, with spaces replaced by dashes
+//	STATUS                NEEDS-ACTION is todo, IN-PROCESS in_progress,
+//	                      COMPLETED completed and CANCELLED deleted
+//	DUE, COMPLETED        Due and CompletedAt; a DUE date without a time is
+//	                      the end of that day
+//	CREATED, LAST-MODIFIED
+//	                      CreatedAt and UpdatedAt
+//	RRULE                 Recur, in the RRULE notation of package recur;
+//	                      rules it does not support are rejected
+//	DTSTART               written with RRULE, which needs it, from Due or
+//	                      else CreatedAt; ignored on import
+//	X-TASKMAN-PROJECT     Project
+//	X-TASKMAN-STATUS      the status, for pending and archived tasks, which
+//	                      STATUS cannot tell apart from todo and completed
+//
+// Other components and properties are ignored.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vkhangstack/taskman/internal/dates"
+	"github.com/vkhangstack/taskman/internal/recur"
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+const (
+	// timeLayout is the UTC form of an iCalendar DATE-TIME
+	timeLayout = "20060102T150405Z"
+	// localLayout is a DATE-TIME without a zone, in floating or TZID time
+	localLayout = "20060102T150405"
+	// dateLayout is an iCalendar DATE
+	dateLayout = "20060102"
+
+	// lineLimit is the longest content line in octets, without the CRLF
+	lineLimit = 75
+
+	projectProperty = "X-TASKMAN-PROJECT"
+	statusProperty  = "X-TASKMAN-STATUS"
+)
+
+// Entry is a task read from a VTODO with the line the component starts on
+type Entry struct {
+	Line int
+	Task *task.Task
+}
+
+// property is a content line: NAME;PARAM=value:VALUE
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the VTODO components of an iCalendar stream. Times without a
+// zone are read in loc.
+func Parse(r io.Reader, loc *time.Location) ([]Entry, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		entries []Entry
+		todo    []property
+		start   int
+		depth   int // components open inside the current VTODO, such as VALARM
+		inTodo  bool
+	)
+	for _, l := range lines {
+		p, err := parseProperty(l.text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", l.number, err)
+		}
+
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VTODO") && !inTodo:
+			inTodo, todo, start = true, nil, l.number
+		case !inTodo:
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END" && depth > 0:
+			depth--
+		case p.name == "END" && strings.EqualFold(p.value, "VTODO"):
+			t, err := toTask(todo, loc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", start, err)
+			}
+			entries = append(entries, Entry{Line: start, Task: t})
+			inTodo = false
+		case depth == 0:
+			todo = append(todo, p)
+		}
+	}
+	if inTodo {
+		return nil, fmt.Errorf("line %d: VTODO is not closed", start)
+	}
+	return entries, nil
+}
+
+// Write writes the tasks as VTODO components of one VCALENDAR
+func Write(w io.Writer, tasks []*task.Task) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		bw.WriteString(fold(name + ":" + value))
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//taskman//taskman//EN")
+	line("CALSCALE", "GREGORIAN")
+	for _, t := range tasks {
+		status, exact := statusOf(t.Status)
+
+		line("BEGIN", "VTODO")
+		line("UID", escape(t.SyncUUID()))
+		line("DTSTAMP", formatTime(t.UpdatedAt))
+		line("CREATED", formatTime(t.CreatedAt))
+		line("LAST-MODIFIED", formatTime(t.UpdatedAt))
+		line("SUMMARY", escape(t.Description))
+		line("PRIORITY", strconv.Itoa(priorityNumber(t.Priority)))
+		line("STATUS", status)
+		if !exact {
+			line(statusProperty, t.Status)
+		}
+		if len(t.Tags) > 0 {
+			escaped := make([]string, len(t.Tags))
+			for i, tag := range t.Tags {
+				escaped[i] = escape(tag)
+			}
+			line("CATEGORIES", strings.Join(escaped, ","))
+		}
+		if t.Project != "" {
+			line(projectProperty, escape(t.Project))
+		}
+		if t.Due != nil {
+			line("DUE", formatTime(*t.Due))
+		}
+		if t.CompletedAt != nil {
+			line("COMPLETED", formatTime(*t.CompletedAt))
+		}
+		if rule, err := recur.Parse(t.Recur); t.Recur != "" && err == nil {
+			line("DTSTART", formatTime(recurStart(t)))
+			line("RRULE", rule.String())
+		}
+		line("END", "VTODO")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// toTask converts the properties of a VTODO
+func toTask(props []property, loc *time.Location) (*task.Task, error) {
+	t := &task.Task{Status: task.StatusTodo, Priority: task.PriorityMedium, Tags: []string{}}
+	var modified, stamp time.Time
+
+	for _, p := range props {
+		var err error
+		switch p.name {
+		case "UID":
+			t.UUID = unescape(p.value)
+		case "SUMMARY":
+			t.Description = strings.TrimSpace(unescape(p.value))
+		case "PRIORITY":
+			n, convErr := strconv.Atoi(strings.TrimSpace(p.value))
+			if convErr != nil || n < 0 || n > 9 {
+				return nil, fmt.Errorf("invalid PRIORITY %q", p.value)
+			}
+			t.Priority = priorityOf(n)
+		case "CATEGORIES":
+			for _, c := range splitList(p.value) {
+				tag := strings.Join(strings.Fields(c), "-")
+				if tag != "" && !t.HasTag(tag) {
+					t.Tags = append(t.Tags, tag)
+				}
+			}
+		case "STATUS":
+			if t.Status, err = statusFrom(p.value); err != nil {
+				return nil, err
+			}
+		case "DUE":
+			var due time.Time
+			if due, err = parseTime(p, loc); err == nil {
+				t.Due = &due
+			}
+		case "COMPLETED":
+			var done time.Time
+			if done, err = parseTime(p, loc); err == nil {
+				t.CompletedAt = &done
+			}
+		case "RRULE":
+			rule, ruleErr := recur.Parse(p.value)
+			if ruleErr != nil {
+				return nil, fmt.Errorf("unsupported RRULE %q: %w", p.value, ruleErr)
+			}
+			t.Recur = rule.String()
+		case "CREATED":
+			t.CreatedAt, err = parseTime(p, loc)
+		case "LAST-MODIFIED":
+			modified, err = parseTime(p, loc)
+		case "DTSTAMP":
+			stamp, err = parseTime(p, loc)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The taskman properties win over STATUS wherever they appear
+	for _, p := range props {
+		switch p.name {
+		case projectProperty:
+			t.Project = unescape(p.value)
+			if err := task.ValidateProject(t.Project); err != nil {
+				return nil, err
+			}
+		case statusProperty:
+			switch s := strings.ToLower(p.value); s {
+			case task.StatusTodo, task.StatusInProgress, task.StatusPending,
+				task.StatusCompleted, task.StatusArchived, task.StatusDeleted:
+				t.Status = s
+			}
+		}
+	}
+
+	if t.Description == "" {
+		return nil, fmt.Errorf("VTODO has no SUMMARY")
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = stamp
+	}
+	switch {
+	case !modified.IsZero():
+		t.UpdatedAt = modified
+	case !stamp.IsZero():
+		t.UpdatedAt = stamp
+	default:
+		t.UpdatedAt = t.CreatedAt
+	}
+	if t.Status != task.StatusCompleted && t.Status != task.StatusArchived {
+		t.CompletedAt = nil
+	}
+	return t, nil
+}
+
+// recurStart is the first occurrence of a recurring task: its due date, or
+// its creation when it has none
+func recurStart(t *task.Task) time.Time {
+	if t.Due != nil {
+		return *t.Due
+	}
+	return t.CreatedAt
+}
+
+// statusOf maps a task status onto a VTODO STATUS, and reports whether the
+// mapping goes back to the same status
+func statusOf(status string) (string, bool) {
+	switch status {
+	case task.StatusInProgress:
+		return "IN-PROCESS", true
+	case task.StatusCompleted:
+		return "COMPLETED", true
+	case task.StatusArchived:
+		return "COMPLETED", false
+	case task.StatusDeleted:
+		return "CANCELLED", true
+	case task.StatusPending:
+		return "NEEDS-ACTION", false
+	default:
+		return "NEEDS-ACTION", true
+	}
+}
+
+// statusFrom maps a VTODO STATUS onto a task status
+func statusFrom(status string) (string, error) {
+	switch strings.ToUpper(strings.TrimSpace(status)) {
+	case "NEEDS-ACTION":
+		return task.StatusTodo, nil
+	case "IN-PROCESS":
+		return task.StatusInProgress, nil
+	case "COMPLETED":
+		return task.StatusCompleted, nil
+	case "CANCELLED":
+		return task.StatusDeleted, nil
+	}
+	return "", fmt.Errorf("invalid STATUS %q", status)
+}
+
+// priorityNumber maps a priority onto the middle of its PRIORITY range
+func priorityNumber(priority string) int {
+	switch priority {
+	case task.PriorityHigh:
+		return 1
+	case task.PriorityLow:
+		return 9
+	default:
+		return 5
+	}
+}
+
+// priorityOf maps a PRIORITY onto a priority
+func priorityOf(n int) string {
+	switch {
+	case n >= 1 && n <= 4:
+		return task.PriorityHigh
+	case n >= 6:
+		return task.PriorityLow
+	default:
+		return task.PriorityMedium
+	}
+}
+
+// formatTime writes a DATE-TIME in UTC
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// parseTime reads a DATE-TIME in UTC, in the zone of its TZID parameter or in
+// loc, or a DATE as the end of that day, and returns it in local time
+func parseTime(p property, loc *time.Location) (time.Time, error) {
+	value := strings.TrimSpace(p.value)
+	if tzid, ok := p.params["TZID"]; ok {
+		if zone, err := time.LoadLocation(tzid); err == nil {
+			loc = zone
+		}
+	}
+
+	if p.params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		d, err := time.ParseInLocation(dateLayout, value, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s %q", p.name, p.value)
+		}
+		return dates.EndOfDay(d).Local(), nil
+	}
+	if t, err := time.Parse(timeLayout, value); err == nil {
+		return t.Local(), nil
+	}
+	t, err := time.ParseInLocation(localLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q", p.name, p.value)
+	}
+	return t.Local(), nil
+}
+
+// numberedLine is an unfolded content line with the line it starts on
+type numberedLine struct {
+	number int
+	text   string
+}
+
+// unfold joins lines that continue on the next line after a space or tab
+func unfold(r io.Reader) ([]numberedLine, error) {
+	var lines []numberedLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if text != "" && (text[0] == ' ' || text[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		if strings.TrimSpace(text) != "" {
+			lines = append(lines, numberedLine{n, text})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read iCalendar file: %w", err)
+	}
+	return lines, nil
+}
+
+// fold ends a content line with CRLF, breaking it into lines of at most
+// lineLimit octets without splitting UTF-8 characters
+func fold(line string) string {
+	var b strings.Builder
+	limit := lineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = lineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// parseProperty splits a content line into its name, parameters and value
+func parseProperty(line string) (property, error) {
+	p := property{params: make(map[string]string)}
+
+	// The value starts at the first colon that is not inside a quoted
+	// parameter value
+	quoted, colon := false, -1
+	for i := 0; i < len(line) && colon < 0; i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				colon = i
+			}
+		}
+	}
+	if colon < 0 {
+		return p, fmt.Errorf("invalid content line %q", line)
+	}
+	p.value = line[colon+1:]
+
+	parts := splitParams(line[:colon])
+	p.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		name, value, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+	return p, nil
+}
+
+// splitParams splits the name and parameters at semicolons outside quotes
+func splitParams(s string) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// escape escapes a TEXT value
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// unescape reads an escaped TEXT value
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// splitList splits a list of TEXT values at unescaped commas and unescapes
+// each of them
+func splitList(s string) []string {
+	var values []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			values = append(values, unescape(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(values, unescape(s[start:]))
+}
@@ -0,0 +1,209 @@
+package ical
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// roundTrip writes the tasks and parses them back
+func roundTrip(t *testing.T, tasks ...*task.Task) ([]*task.Task, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, tasks); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	entries, err := Parse(bytes.NewReader(buf.Bytes()), time.UTC)
+	if err != nil {
+		t.Fatalf("Parse: %v\n%s", err, buf.String())
+	}
+
+	var parsed []*task.Task
+	for _, e := range entries {
+		parsed = append(parsed, e.Task)
+	}
+	if len(parsed) != len(tasks) {
+		t.Fatalf("got %d tasks back, want %d", len(parsed), len(tasks))
+	}
+	return parsed, buf.String()
+}
+
+// newTask returns a task with the fields every VTODO carries
+func newTask(desc string) *task.Task {
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	return &task.Task{
+		UUID:        "6f1c2a4e-8d1b-4c52-9b3e-1a2b3c4d5e01",
+		Description: desc,
+		Status:      task.StatusTodo,
+		Priority:    task.PriorityMedium,
+		Tags:        []string{},
+		CreatedAt:   created,
+		UpdatedAt:   created.Add(time.Hour),
+	}
+}
+
+func TestRoundTripFields(t *testing.T) {
+	in := newTask("Call the bank")
+	in.Priority = task.PriorityHigh
+	in.Status = task.StatusArchived
+	in.Project = "home.finance"
+	in.Tags = []string{"phone", "errand"}
+	due := time.Date(2026, 3, 5, 17, 30, 0, 0, time.UTC)
+	done := time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC)
+	in.Due, in.CompletedAt = &due, &done
+
+	got, _ := roundTrip(t, in)
+	out := got[0]
+
+	if out.UUID != in.UUID || out.Description != in.Description || out.Priority != in.Priority ||
+		out.Status != in.Status || out.Project != in.Project {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+	if !slices.Equal(out.Tags, in.Tags) {
+		t.Errorf("Tags = %q, want %q", out.Tags, in.Tags)
+	}
+	if out.Due == nil || !out.Due.Equal(due) {
+		t.Errorf("Due = %v, want %v", out.Due, due)
+	}
+	if out.CompletedAt == nil || !out.CompletedAt.Equal(done) {
+		t.Errorf("CompletedAt = %v, want %v", out.CompletedAt, done)
+	}
+	if !out.CreatedAt.Equal(in.CreatedAt) || !out.UpdatedAt.Equal(in.UpdatedAt) {
+		t.Errorf("CreatedAt, UpdatedAt = %v, %v, want %v, %v", out.CreatedAt, out.UpdatedAt, in.CreatedAt, in.UpdatedAt)
+	}
+}
+
+func TestRoundTripRRule(t *testing.T) {
+	tests := []struct{ recur, rrule string }{
+		{"weekly:mon,thu", "FREQ=WEEKLY;BYDAY=MO,TH"},
+		{"monthly:-1", "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{"every:2w", "FREQ=WEEKLY;INTERVAL=2"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,15;UNTIL=20271231", "FREQ=MONTHLY;BYMONTHDAY=1,15;UNTIL=20271231"},
+	}
+	for _, tt := range tests {
+		in := newTask("Water plants")
+		in.Recur = tt.recur
+
+		got, text := roundTrip(t, in)
+		if !strings.Contains(text, "\r\nRRULE:"+tt.rrule+"\r\n") {
+			t.Errorf("%s is not written as RRULE:%s\n%s", tt.recur, tt.rrule, text)
+		}
+		if got[0].Recur != tt.rrule {
+			t.Errorf("%s reads back as %q, want %q", tt.recur, got[0].Recur, tt.rrule)
+		}
+	}
+}
+
+func TestWriteRRuleStart(t *testing.T) {
+	due := time.Date(2026, 3, 5, 17, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		due   *time.Time
+		start string
+	}{
+		{"due", &due, "20260305T173000Z"},
+		{"no due", nil, "20260301T090000Z"},
+	}
+	for _, tt := range tests {
+		in := newTask("Water plants")
+		in.Recur = "weekly"
+		in.Due = tt.due
+
+		got, text := roundTrip(t, in)
+		if !strings.Contains(text, "\r\nDTSTART:"+tt.start+"\r\nRRULE:FREQ=WEEKLY\r\n") {
+			t.Errorf("%s: RRULE is not written after DTSTART:%s\n%s", tt.name, tt.start, text)
+		}
+		// DTSTART does not turn into a due date
+		if (got[0].Due == nil) != (tt.due == nil) {
+			t.Errorf("%s: Due = %v, want %v", tt.name, got[0].Due, tt.due)
+		}
+	}
+
+	// Tasks that do not recur have no DTSTART
+	_, text := roundTrip(t, newTask("Call the bank"))
+	if strings.Contains(text, "DTSTART") {
+		t.Errorf("DTSTART written without RRULE\n%s", text)
+	}
+}
+
+func TestParseRejectsUnsupportedRRule(t *testing.T) {
+	doc := "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:a\r\nSUMMARY:x\r\nRRULE:FREQ=HOURLY\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	if _, err := Parse(strings.NewReader(doc), time.UTC); err == nil {
+		t.Error("Parse accepted FREQ=HOURLY")
+	}
+}
+
+func TestParseDue(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	tests := []struct {
+		line string
+		want time.Time
+	}{
+		{"DUE:20260305T170000Z", time.Date(2026, 3, 5, 17, 0, 0, 0, time.UTC)},
+		{"DUE;VALUE=DATE:20260305", time.Date(2026, 3, 5, 23, 59, 59, 0, loc)},
+		{"DUE:20260305T120000", time.Date(2026, 3, 5, 12, 0, 0, 0, loc)},
+		{"DUE;TZID=Europe/Berlin:20260305T120000", time.Date(2026, 3, 5, 11, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		doc := "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:a\r\nSUMMARY:x\r\n" + tt.line + "\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+		entries, err := Parse(strings.NewReader(doc), loc)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.line, err)
+		}
+		due := entries[0].Task.Due
+		if due == nil || !due.Equal(tt.want) {
+			t.Errorf("%s: Due = %v, want %v", tt.line, due, tt.want)
+		}
+	}
+}
+
+func TestRoundTripEscapedText(t *testing.T) {
+	in := newTask("Buy milk, eggs; bread\\butter\nand jam")
+	in.Tags = []string{"a,b", "semi;colon"}
+	in.Project = "home"
+
+	got, text := roundTrip(t, in)
+	if !strings.Contains(text, `SUMMARY:Buy milk\, eggs\; bread\\butter\nand jam`) {
+		t.Errorf("SUMMARY is not escaped:\n%s", text)
+	}
+	if !strings.Contains(text, `CATEGORIES:a\,b,semi\;colon`) {
+		t.Errorf("CATEGORIES are not escaped:\n%s", text)
+	}
+	if got[0].Description != in.Description {
+		t.Errorf("Description = %q, want %q", got[0].Description, in.Description)
+	}
+	if !slices.Equal(got[0].Tags, in.Tags) {
+		t.Errorf("Tags = %q, want %q", got[0].Tags, in.Tags)
+	}
+}
+
+func TestRoundTripFoldedLines(t *testing.T) {
+	in := newTask(strings.Repeat("Prüfen und freigeben – ", 12))
+	in.Description = strings.TrimSpace(in.Description)
+
+	got, text := roundTrip(t, in)
+	if !strings.HasSuffix(text, "\r\n") {
+		t.Error("output does not end with CRLF")
+	}
+	folded := false
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\r\n"), "\r\n") {
+		if len(line) > lineLimit {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a UTF-8 character: %q", line)
+		}
+		folded = folded || strings.HasPrefix(line, " ")
+	}
+	if !folded {
+		t.Error("the long SUMMARY was not folded")
+	}
+	if got[0].Description != in.Description {
+		t.Errorf("Description = %q, want %q", got[0].Description, in.Description)
+	}
+}
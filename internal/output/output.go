@@ -0,0 +1,256 @@
+// Package output writes command results in machine-readable formats for the
+// global --output flag.
+//
+// Every command reports a Result. The json and yaml formats write the Result as
+// one document:
+//
+//	{
+//	  "command": "complete",
+//	  "ok": false,
+//	  "created_id": 0,          // omitted unless the command created a task
+//	  "tasks": [ {task}, ... ], // tasks listed, shown or affected by the command
+//	  "errors": [ {"id": 7, "message": "task with ID 7 not found"} ]
+//	}
+//
+// ndjson writes one JSON object per line: {"created_id": N} if the command
+// created a task, then {"task": {task}} for every task followed by
+// {"error": {error}} for every error. csv writes a header and one row per task,
+// with created_id set on the row of the created task, followed by one row per
+// error with only the id and error columns set.
+//
+// Tasks are encoded with the same field names as the JSON store, and times use
+// RFC 3339. Fields are only ever added to this schema, never renamed or removed.
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vkhangstack/taskman/internal/task"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a machine-readable output format
+type Format string
+
+const (
+	Text   Format = "" // human-readable tables and messages
+	JSON   Format = "json"
+	YAML   Format = "yaml"
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+)
+
+// ParseFormat validates the value of the --output flag
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case Text, JSON, YAML, CSV, NDJSON:
+		return f, nil
+	case "text", "table":
+		return Text, nil
+	default:
+		return Text, fmt.Errorf("invalid output format: %s. Valid formats are: json, yaml, csv, ndjson", s)
+	}
+}
+
+// Result is the outcome of a command
+type Result struct {
+	Command   string       `json:"command"`
+	OK        bool         `json:"ok"`
+	CreatedID int          `json:"created_id,omitempty"`
+	Tasks     []*task.Task `json:"tasks"`
+	Errors    []Error      `json:"errors,omitempty"`
+}
+
+// Error is a failure affecting a single task, or the whole command when ID is 0
+type Error struct {
+	ID      int    `json:"id,omitempty"`
+	Message string `json:"message"`
+}
+
+// NewResult returns an empty successful result for the command
+func NewResult(command string) *Result {
+	return &Result{Command: command, OK: true, Tasks: []*task.Task{}}
+}
+
+// AddTask records a task listed or affected by the command. Tasks without tags
+// are recorded with an empty list, so that tags never encode as null.
+func (r *Result) AddTask(t *task.Task) {
+	if t.Tags == nil {
+		c := *t
+		c.Tags = []string{}
+		t = &c
+	}
+	r.Tasks = append(r.Tasks, t)
+}
+
+// AddError records a failure for the task with the given ID and marks the
+// result as not OK
+func (r *Result) AddError(id int, err error) {
+	r.OK = false
+	r.Errors = append(r.Errors, Error{ID: id, Message: err.Error()})
+}
+
+// Write encodes the result to w in the given format
+func Write(w io.Writer, format Format, r *Result) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case YAML:
+		return writeYAML(w, r)
+	case NDJSON:
+		return writeNDJSON(w, r)
+	case CSV:
+		return writeCSV(w, r)
+	default:
+		return fmt.Errorf("unsupported output format: %q", format)
+	}
+}
+
+// writeYAML converts the JSON encoding to YAML so that both formats share the
+// same field names and order
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to convert result to yaml: %w", err)
+	}
+	blockStyle(&doc)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to write yaml: %w", err)
+	}
+	return enc.Close()
+}
+
+// blockStyle drops the flow and quoting styles that parsing JSON leaves behind
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+func writeNDJSON(w io.Writer, r *Result) error {
+	enc := json.NewEncoder(w)
+	if r.CreatedID != 0 {
+		if err := enc.Encode(struct {
+			CreatedID int `json:"created_id"`
+		}{r.CreatedID}); err != nil {
+			return err
+		}
+	}
+	for _, t := range r.Tasks {
+		if err := enc.Encode(struct {
+			Task *task.Task `json:"task"`
+		}{t}); err != nil {
+			return err
+		}
+	}
+	for _, e := range r.Errors {
+		if err := enc.Encode(struct {
+			Error Error `json:"error"`
+		}{e}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CSVHeader lists the columns written by the csv format. New columns are
+// added at the end.
+var CSVHeader = []string{
+	"id", "description", "status", "priority", "tags", "due", "recur",
+	"created_at", "updated_at", "completed_at", "error",
+	"project", "parent_id", "depends_on", "created_id",
+}
+
+// csvError is the index of the error column
+const csvError = 10
+
+func writeCSV(w io.Writer, r *Result) error {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	cw.Write(CSVHeader)
+
+	for _, t := range r.Tasks {
+		cw.Write([]string{
+			strconv.Itoa(t.ID),
+			t.Description,
+			t.Status,
+			t.Priority,
+			strings.Join(t.Tags, ";"),
+			formatTime(t.Due),
+			t.Recur,
+			formatTime(&t.CreatedAt),
+			formatTime(&t.UpdatedAt),
+			formatTime(t.CompletedAt),
+			"",
+			t.Project,
+			formatID(t.ParentID),
+			joinIDs(t.DependsOn),
+			formatID(createdID(r, t)),
+		})
+	}
+	for _, e := range r.Errors {
+		row := make([]string, len(CSVHeader))
+		if e.ID != 0 {
+			row[0] = strconv.Itoa(e.ID)
+		}
+		row[csvError] = e.Message
+		cw.Write(row)
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// createdID returns the ID of the task if the command created it, or 0
+func createdID(r *Result, t *task.Task) int {
+	if r.CreatedID != 0 && t.ID == r.CreatedID {
+		return t.ID
+	}
+	return 0
+}
+
+// formatID formats a task ID, leaving 0 empty
+func formatID(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+// joinIDs joins task IDs with ";", like tags
+func joinIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ";")
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
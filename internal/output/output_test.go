@@ -0,0 +1,92 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// newAddResult returns the result of adding a subtask
+func newAddResult() *Result {
+	r := NewResult("add")
+	r.CreatedID = 3
+	r.AddTask(&task.Task{ID: 3, Description: "Write tests", Status: "todo", Priority: "high",
+		Project: "work.backend", ParentID: 1, DependsOn: []int{1, 2}})
+	return r
+}
+
+func TestWriteNDJSONCreatedID(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, NDJSON, newAddResult()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	if lines[0] != `{"created_id":3}` {
+		t.Errorf("first line = %s, want the created ID", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], `{"task":{"id":3,`) {
+		t.Errorf("second line = %s, want the task", lines[1])
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	r := newAddResult()
+	r.AddError(9, errors.New("task with ID 9 not found"))
+
+	var buf bytes.Buffer
+	if err := Write(&buf, CSV, r); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output does not parse as csv: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want header, task and error", len(rows))
+	}
+
+	get := func(row []string, column string) string {
+		for i, name := range CSVHeader {
+			if name == column {
+				return row[i]
+			}
+		}
+		t.Fatalf("no column %s", column)
+		return ""
+	}
+	for column, want := range map[string]string{
+		"id": "3", "project": "work.backend", "parent_id": "1", "depends_on": "1;2", "created_id": "3", "error": "",
+	} {
+		if got := get(rows[1], column); got != want {
+			t.Errorf("task row %s = %q, want %q", column, got, want)
+		}
+	}
+	if get(rows[2], "id") != "9" || get(rows[2], "error") == "" || get(rows[2], "created_id") != "" {
+		t.Errorf("error row = %q", rows[2])
+	}
+}
+
+func TestWriteJSONTagsNeverNull(t *testing.T) {
+	r := NewResult("list")
+	r.AddTask(&task.Task{ID: 1, Description: "untagged"})
+	r.AddTask(&task.Task{ID: 2, Description: "tagged", Tags: []string{"work"}})
+
+	var buf bytes.Buffer
+	if err := Write(&buf, JSON, r); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if strings.Contains(buf.String(), `"tags": null`) {
+		t.Errorf("tags encoded as null:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `"tags": []`) {
+		t.Errorf("untagged task has no empty tags list:\n%s", buf.String())
+	}
+}
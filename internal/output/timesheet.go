@@ -0,0 +1,134 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// Timesheet is the export of the timesheet command. Unlike the table, which
+// rounds to minutes, it lists every time entry with its exact start, end and
+// duration in seconds, clipped to the reported period:
+//
+//	{
+//	  "command": "timesheet",
+//	  "from": "2026-03-02T00:00:00+01:00",
+//	  "to": "2026-03-09T00:00:00+01:00",
+//	  "total_seconds": 5400,
+//	  "entries": [ {"task_id": 3, "start": ..., "end": ..., "seconds": 5400, ...} ]
+//	}
+//
+// ndjson writes one {"entry": {entry}} line per entry and csv one row per
+// entry. Entries of running timers end at the time of the export and have
+// running set.
+type Timesheet struct {
+	Command      string      `json:"command"`
+	From         time.Time   `json:"from"`
+	To           time.Time   `json:"to"`
+	TotalSeconds int64       `json:"total_seconds"`
+	Entries      []TimeEntry `json:"entries"`
+}
+
+// TimeEntry is an interval of tracked time in a timesheet
+type TimeEntry struct {
+	TaskID      int       `json:"task_id"`
+	Description string    `json:"description"`
+	Project     string    `json:"project,omitempty"`
+	Tags        []string  `json:"tags"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Seconds     int64     `json:"seconds"`
+	Running     bool      `json:"running,omitempty"`
+	Note        string    `json:"note,omitempty"`
+}
+
+// NewTimesheet returns the timesheet of the slices between from and to
+func NewTimesheet(from, to time.Time, slices []task.TimeSlice) *Timesheet {
+	ts := &Timesheet{Command: "timesheet", From: from, To: to, Entries: []TimeEntry{}}
+	for _, s := range slices {
+		tags := s.Task.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		seconds := int64(s.Duration() / time.Second)
+		ts.TotalSeconds += seconds
+		ts.Entries = append(ts.Entries, TimeEntry{
+			TaskID:      s.Task.ID,
+			Description: s.Task.Description,
+			Project:     s.Task.Project,
+			Tags:        tags,
+			Start:       s.Start,
+			End:         s.End,
+			Seconds:     seconds,
+			Running:     s.Running,
+			Note:        s.Note,
+		})
+	}
+	return ts
+}
+
+// TimesheetCSVHeader lists the columns written by the csv format
+var TimesheetCSVHeader = []string{
+	"task_id", "description", "project", "tags", "start", "end", "seconds", "hours", "running", "note",
+}
+
+// WriteTimesheet encodes the timesheet to w in the given format
+func WriteTimesheet(w io.Writer, format Format, ts *Timesheet) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(ts)
+	case YAML:
+		return writeYAML(w, ts)
+	case NDJSON:
+		enc := json.NewEncoder(w)
+		for _, e := range ts.Entries {
+			if err := enc.Encode(struct {
+				Entry TimeEntry `json:"entry"`
+			}{e}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSV:
+		return writeTimesheetCSV(w, ts)
+	default:
+		return fmt.Errorf("unsupported output format: %q", format)
+	}
+}
+
+func writeTimesheetCSV(w io.Writer, ts *Timesheet) error {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	cw.Write(TimesheetCSVHeader)
+
+	for _, e := range ts.Entries {
+		cw.Write([]string{
+			strconv.Itoa(e.TaskID),
+			e.Description,
+			e.Project,
+			strings.Join(e.Tags, ";"),
+			formatTime(&e.Start),
+			formatTime(&e.End),
+			strconv.FormatInt(e.Seconds, 10),
+			strconv.FormatFloat(float64(e.Seconds)/3600, 'f', 4, 64),
+			strconv.FormatBool(e.Running),
+			e.Note,
+		})
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
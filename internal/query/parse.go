@@ -0,0 +1,288 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ParseError reports a syntax error and where in the input it occurred
+type ParseError struct {
+	Input string
+	Pos   int // byte offset into Input
+	Msg   string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid filter: %s at position %d\n  %s\n  %s^", e.Msg, e.Pos+1, e.Input, strings.Repeat(" ", e.Pos))
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+	tokTerm
+)
+
+type token struct {
+	kind tokenKind
+	pos  int
+	text string // raw text of the token
+
+	// Set for tokTerm
+	field string
+	op    string // ":", "~", "+" or "-"
+	value string
+}
+
+// lex splits the input into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+
+	for i < len(input) {
+		c := rune(input[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, pos: i, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, pos: i, text: ")"})
+			i++
+		case c == '!':
+			tokens = append(tokens, token{kind: tokNot, pos: i, text: "!"})
+			i++
+		default:
+			tok, next, err := lexTerm(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = next
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(input)}), nil
+}
+
+// lexTerm reads a word, a +tag/-tag, a field:value or field~value term, or a
+// quoted string starting at pos
+func lexTerm(input string, pos int) (token, int, error) {
+	tok := token{kind: tokTerm, pos: pos}
+	i := pos
+
+	// Quoted strings on their own search the description
+	if input[i] == '"' || input[i] == '\'' {
+		value, next, err := lexQuoted(input, i)
+		if err != nil {
+			return tok, 0, err
+		}
+		tok.field, tok.op, tok.value, tok.text = "description", "~", value, input[pos:next]
+		return tok, next, nil
+	}
+
+	if input[i] == '+' || input[i] == '-' {
+		tok.op = input[i : i+1]
+		i++
+		start := i
+		for i < len(input) && isWordByte(input[i]) {
+			i++
+		}
+		if i == start {
+			return tok, 0, &ParseError{Input: input, Pos: start, Msg: fmt.Sprintf("expected a tag name after %q", tok.op)}
+		}
+		tok.field, tok.value, tok.text = "tag", input[start:i], input[pos:i]
+		return tok, i, nil
+	}
+
+	start := i
+	for i < len(input) && (isWordByte(input[i]) || input[i] == '.') {
+		i++
+	}
+	word := input[start:i]
+
+	if i < len(input) && (input[i] == ':' || input[i] == '~') {
+		if word == "" {
+			return tok, 0, &ParseError{Input: input, Pos: i, Msg: fmt.Sprintf("expected a field name before %q", input[i])}
+		}
+		tok.field, tok.op = strings.ToLower(word), input[i:i+1]
+		i++
+
+		if i < len(input) && (input[i] == '"' || input[i] == '\'') {
+			value, next, err := lexQuoted(input, i)
+			if err != nil {
+				return tok, 0, err
+			}
+			tok.value, i = value, next
+		} else {
+			vstart := i
+			for i < len(input) && !unicode.IsSpace(rune(input[i])) && input[i] != ')' && input[i] != '(' {
+				i++
+			}
+			tok.value = input[vstart:i]
+		}
+		tok.text = input[pos:i]
+		// An empty value would match every task, which a typo should not do
+		if tok.value == "" {
+			return tok, 0, &ParseError{Input: input, Pos: start + len(word) + 1, Msg: fmt.Sprintf("expected a value after %q", word+tok.op)}
+		}
+		return tok, i, nil
+	}
+
+	if word == "" {
+		return tok, 0, &ParseError{Input: input, Pos: i, Msg: fmt.Sprintf("unexpected %q", input[i])}
+	}
+
+	tok.text = word
+	switch strings.ToLower(word) {
+	case "and":
+		tok.kind = tokAnd
+	case "or":
+		tok.kind = tokOr
+	case "not":
+		tok.kind = tokNot
+	default:
+		// Bare words search the description
+		tok.field, tok.op, tok.value = "description", "~", word
+	}
+	return tok, i, nil
+}
+
+// lexQuoted reads a quoted string starting at pos and returns its unquoted
+// value. A backslash escapes the next character.
+func lexQuoted(input string, pos int) (string, int, error) {
+	quote := input[pos]
+	var b strings.Builder
+	for i := pos + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			if i+1 < len(input) {
+				i++
+				b.WriteByte(input[i])
+			}
+		case quote:
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(input[i])
+		}
+	}
+	return "", 0, &ParseError{Input: input, Pos: pos, Msg: "unterminated string"}
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '-' || c == '/' || c == '@' || c == '#' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
+
+// parser is a recursive descent parser over the token list:
+//
+//	expr    := and ("or" and)*
+//	and     := unary (["and"] unary)*
+//	unary   := ("not" | "!") unary | primary
+//	primary := "(" expr ")" | term
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+	now    time.Time
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return &ParseError{Input: p.input, Pos: tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseExpr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.next()
+		case tokNot, tokLParen, tokTerm:
+			// Adjacent terms are implicitly and-ed
+		default:
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if p.peek().kind == tokNot {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokLParen:
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokRParen {
+			return nil, p.errorf(tok, "unclosed '('")
+		}
+		return expr, nil
+	case tokTerm:
+		return p.compileTerm(tok)
+	case tokEOF:
+		return nil, p.errorf(tok, "unexpected end of filter")
+	default:
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+}
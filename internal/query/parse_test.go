@@ -0,0 +1,98 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)
+
+func TestParseStructure(t *testing.T) {
+	tests := []struct{ input, want string }{
+		{"", ""},
+		{"deploy", "deploy"},
+		{"a b", "(a and b)"},
+		{"a and b", "(a and b)"},
+		{"a or b", "(a or b)"},
+		{"a or b c", "(a or (b and c))"},
+		{"a b or c", "((a and b) or c)"},
+		{"a or b and c or d", "((a or (b and c)) or d)"},
+		{"(a or b) c", "((a or b) and c)"},
+		{"a (b or (c d))", "(a and (b or (c and d)))"},
+		{"not a b", "(not a and b)"},
+		{"!a or b", "(not a or b)"},
+		{"not (a or b)", "not (a or b)"},
+		{"not not a", "not not a"},
+		{"A AND b OR Not c", "((A and b) or not c)"},
+		{"+work -home", "(+work and -home)"},
+		{`priority:high desc~"deploy now"`, `(priority:high and desc~"deploy now")`},
+		{"status:todo,pending project:team.web", "(status:todo,pending and project:team.web)"},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.input, testNow)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.input, err)
+			continue
+		}
+		if got := q.String(); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		msg   string
+		pos   int
+	}{
+		{"status:", `expected a value after "status:"`, 7},
+		{"desc~", `expected a value after "desc~"`, 5},
+		{`desc:""`, `expected a value after "desc:"`, 5},
+		{"a and (tag: or b)", `expected a value after "tag:"`, 11},
+		{"+", `expected a tag name after "+"`, 1},
+		{"a -", `expected a tag name after "-"`, 3},
+		{":high", `expected a field name before ':'`, 0},
+		{"a ]", `unexpected ']'`, 2},
+		{`desc:"deploy`, "unterminated string", 5},
+		{"(a or b", "unclosed '('", 0},
+		{"a (b (c)", "unclosed '('", 2},
+		{"a )", `unexpected ")"`, 2},
+		{"a and", "unexpected end of filter", 5},
+		{"not", "unexpected end of filter", 3},
+		{"colour:red", `unknown field "colour" (valid fields: completed, created, description, due, id, parent, priority, project, recur, status, tag, updated)`, 0},
+		{"desc.is~x", `'~' cannot be combined with the "is" modifier`, 0},
+		{"tag.has:x", `unsupported modifier "has" for tag (use is or not)`, 0},
+		{"+FOO", `unknown virtual tag "FOO" (valid: +BLOCKED, +BLOCKING, +COMPLETED, +DUE, +DUETODAY, +OPEN, +OVERDUE, +RECURRING, +TAGGED)`, 0},
+		{"status.before:todo", `unsupported modifier "before" for status (use is, not or has)`, 0},
+		{"id:1,x", `invalid task ID "x"`, 0},
+		{"parent.has:1", `unsupported modifier "has" for parent (use is or not)`, 0},
+		{"due.before:none", "due.before cannot be compared with none", 0},
+		{"due:someday", `invalid date: "someday" (try 2026-01-31, tomorrow, fri, +3d, eow or eom)`, 0},
+		{"due.has:today", `unsupported modifier "has" for due (use before, after, is or not)`, 0},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input, testNow)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("Parse(%q) error = %v, want a ParseError", tt.input, err)
+			continue
+		}
+		if perr.Msg != tt.msg || perr.Pos != tt.pos {
+			t.Errorf("Parse(%q) error = %q at %d, want %q at %d", tt.input, perr.Msg, perr.Pos, tt.msg, tt.pos)
+		}
+		if strings.Count(err.Error(), "position") != 1 {
+			t.Errorf("Parse(%q) error %q does not give the position once", tt.input, err)
+		}
+	}
+}
+
+func TestParseErrorString(t *testing.T) {
+	_, err := Parse("a and (b or c", testNow)
+	want := "invalid filter: unclosed '(' at position 7\n  a and (b or c\n        ^"
+	if err == nil || err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
@@ -0,0 +1,409 @@
+// Package query implements the filter language used by list and the bulk
+// commands, for example:
+//
+//	priority:high and (+work or +urgent) and -blocked and created.after:2026-01-01 and desc~"deploy"
+//
+// A filter is a boolean expression of terms combined with and, or, not (or !)
+// and parentheses. Adjacent terms are and-ed. Terms are:
+//
+//	+tag, -tag              the task has (or lacks) a tag
+//	+OVERDUE, +BLOCKED      virtual tags, see VirtualTags
+//	field:value             status, priority, project, id, parent, tag, recur, description
+//	field~value             the field contains value (case-insensitive)
+//	field.mod:value         modifiers is, not, has, before and after
+//	word, "some words"      the description contains the text
+//
+// status, priority and id accept comma separated alternatives (status:todo,pending).
+// Date fields (created, updated, due, completed) take dates understood by
+// package dates, or "none"; field:date matches the same day.
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vkhangstack/taskman/internal/dates"
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// Env holds what terms need beyond the task itself
+type Env struct {
+	Now time.Time
+	// Tasks indexes every task by ID, for terms that depend on other tasks
+	Tasks map[int]*task.Task
+}
+
+// Node is a node of a parsed filter expression
+type Node interface {
+	Match(t *task.Task, env *Env) bool
+	String() string
+}
+
+// And matches tasks matched by both operands
+type And struct{ Left, Right Node }
+
+func (n *And) Match(t *task.Task, env *Env) bool {
+	return n.Left.Match(t, env) && n.Right.Match(t, env)
+}
+func (n *And) String() string { return fmt.Sprintf("(%s and %s)", n.Left, n.Right) }
+
+// Or matches tasks matched by either operand
+type Or struct{ Left, Right Node }
+
+func (n *Or) Match(t *task.Task, env *Env) bool { return n.Left.Match(t, env) || n.Right.Match(t, env) }
+func (n *Or) String() string                    { return fmt.Sprintf("(%s or %s)", n.Left, n.Right) }
+
+// Not matches tasks not matched by its operand
+type Not struct{ Operand Node }
+
+func (n *Not) Match(t *task.Task, env *Env) bool { return !n.Operand.Match(t, env) }
+func (n *Not) String() string                    { return fmt.Sprintf("not %s", n.Operand) }
+
+// Term is a single comparison such as priority:high or +work
+type Term struct {
+	Source string
+	match  func(t *task.Task, env *Env) bool
+}
+
+func (n *Term) Match(t *task.Task, env *Env) bool { return n.match(t, env) }
+func (n *Term) String() string                    { return n.Source }
+
+// VirtualTags are upper-case tags derived from task state rather than stored
+var VirtualTags = map[string]func(t *task.Task, env *Env) bool{
+	"OPEN":      func(t *task.Task, env *Env) bool { return t.IsOpen() },
+	"COMPLETED": func(t *task.Task, env *Env) bool { return t.IsCompleted() },
+	"OVERDUE":   func(t *task.Task, env *Env) bool { return t.IsOverdue(env.Now) },
+	"DUETODAY":  func(t *task.Task, env *Env) bool { return t.IsDueToday(env.Now) },
+	"DUE":       func(t *task.Task, env *Env) bool { return t.Due != nil },
+	"RECURRING": func(t *task.Task, env *Env) bool { return t.IsRecurring() },
+	"TAGGED":    func(t *task.Task, env *Env) bool { return len(t.Tags) > 0 },
+	"BLOCKED":   func(t *task.Task, env *Env) bool { return t.IsBlocked(env.Tasks) },
+	"BLOCKING":  func(t *task.Task, env *Env) bool { return t.IsBlocking(env.Tasks) },
+}
+
+// Query is a parsed filter
+type Query struct {
+	Source string
+	Root   Node // nil for an empty filter, which matches every task
+	now    time.Time
+}
+
+// Parse parses a filter expression. Relative dates are resolved against now.
+func Parse(input string, now time.Time) (*Query, error) {
+	q := &Query{Source: input, now: now}
+	if strings.TrimSpace(input) == "" {
+		return q, nil
+	}
+
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{input: input, tokens: tokens, now: now}
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+
+	q.Root = root
+	return q, nil
+}
+
+// IsEmpty returns true if the filter matches every task
+func (q *Query) IsEmpty() bool {
+	return q.Root == nil
+}
+
+// Match returns true if the task matches the filter
+func (q *Query) Match(t *task.Task, env *Env) bool {
+	return q.Root == nil || q.Root.Match(t, env)
+}
+
+// Filter returns the tasks matching the filter, keeping their order
+func (q *Query) Filter(tasks []*task.Task) []*task.Task {
+	env := NewEnv(tasks, q.now)
+
+	var matched []*task.Task
+	for _, t := range tasks {
+		if q.Match(t, env) {
+			matched = append(matched, t)
+		}
+	}
+	return matched
+}
+
+// NewEnv builds an Env indexing the given tasks
+func NewEnv(tasks []*task.Task, now time.Time) *Env {
+	env := &Env{Now: now, Tasks: make(map[int]*task.Task, len(tasks))}
+	for _, t := range tasks {
+		env.Tasks[t.ID] = t
+	}
+	return env
+}
+
+func (q *Query) String() string {
+	if q.Root == nil {
+		return ""
+	}
+	return q.Root.String()
+}
+
+var fieldAliases = map[string]string{
+	"desc":        "description",
+	"description": "description",
+	"status":      "status",
+	"priority":    "priority",
+	"pri":         "priority",
+	"id":          "id",
+	"parent":      "parent",
+	"project":     "project",
+	"proj":        "project",
+	"tag":         "tag",
+	"tags":        "tag",
+	"recur":       "recur",
+	"created":     "created",
+	"entry":       "created",
+	"updated":     "updated",
+	"modified":    "updated",
+	"due":         "due",
+	"completed":   "completed",
+	"end":         "completed",
+}
+
+// compileTerm turns a term token into a Term, validating its field, modifier
+// and value
+func (p *parser) compileTerm(tok token) (Node, error) {
+	term := &Term{Source: tok.text}
+
+	if tok.op == "+" || tok.op == "-" {
+		match, err := p.tagMatcher(tok, tok.value)
+		if err != nil {
+			return nil, err
+		}
+		term.match = match
+		if tok.op == "-" {
+			term.match = negate(match)
+		}
+		return term, nil
+	}
+
+	name, modifier, _ := strings.Cut(tok.field, ".")
+	field, ok := fieldAliases[name]
+	if !ok {
+		return nil, p.errorf(tok, "unknown field %q (valid fields: %s)", name, strings.Join(fieldNames(), ", "))
+	}
+
+	if tok.op == "~" {
+		if modifier != "" {
+			return nil, p.errorf(tok, "'~' cannot be combined with the %q modifier", modifier)
+		}
+		modifier = "has"
+	}
+
+	var (
+		match func(t *task.Task, env *Env) bool
+		err   error
+	)
+	switch field {
+	case "created", "updated", "due", "completed":
+		match, err = p.dateMatcher(tok, field, modifier)
+	case "id":
+		match, err = p.idMatcher(tok, modifier, func(t *task.Task) int { return t.ID })
+	case "parent":
+		match, err = p.idMatcher(tok, modifier, func(t *task.Task) int { return t.ParentID })
+	case "tag":
+		if modifier != "" && modifier != "is" && modifier != "not" {
+			return nil, p.errorf(tok, "unsupported modifier %q for tag (use is or not)", modifier)
+		}
+		match, err = p.tagMatcher(tok, tok.value)
+		if modifier == "not" {
+			match = negate(match)
+		}
+	default:
+		match, err = p.stringMatcher(tok, field, modifier)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	term.match = match
+	return term, nil
+}
+
+func (p *parser) tagMatcher(tok token, tag string) (func(t *task.Task, env *Env) bool, error) {
+	if tag == "" {
+		return nil, p.errorf(tok, "expected a tag name")
+	}
+	if virtual, ok := VirtualTags[tag]; ok {
+		return virtual, nil
+	}
+	if strings.ToUpper(tag) == tag && strings.ToLower(tag) != tag {
+		return nil, p.errorf(tok, "unknown virtual tag %q (valid: %s)", tag, strings.Join(virtualTagNames(), ", "))
+	}
+	return func(t *task.Task, env *Env) bool { return t.HasTag(tag) }, nil
+}
+
+func (p *parser) stringMatcher(tok token, field, modifier string) (func(t *task.Task, env *Env) bool, error) {
+	get := func(t *task.Task) string {
+		switch field {
+		case "description":
+			return t.Description
+		case "status":
+			return t.Status
+		case "priority":
+			return t.Priority
+		case "project":
+			return t.Project
+		default:
+			return t.Recur
+		}
+	}
+
+	value := strings.ToLower(tok.value)
+	contains := func(t *task.Task, env *Env) bool { return strings.Contains(strings.ToLower(get(t)), value) }
+	equals := func(t *task.Task, env *Env) bool {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(get(t), v) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Descriptions match substrings by default, projects include their
+	// subprojects and the other fields match exact values
+	def := equals
+	switch field {
+	case "description":
+		def = contains
+	case "project":
+		def = func(t *task.Task, env *Env) bool {
+			for _, v := range strings.Split(value, ",") {
+				if task.IsSubproject(strings.ToLower(t.Project), v) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	switch modifier {
+	case "":
+		return def, nil
+	case "is":
+		return equals, nil
+	case "not":
+		return negate(def), nil
+	case "has":
+		return contains, nil
+	default:
+		return nil, p.errorf(tok, "unsupported modifier %q for %s (use is, not or has)", modifier, field)
+	}
+}
+
+// idMatcher matches task IDs returned by get against a list of IDs, where
+// "none" stands for 0 (a task without a parent)
+func (p *parser) idMatcher(tok token, modifier string, get func(t *task.Task) int) (func(t *task.Task, env *Env) bool, error) {
+	ids := make(map[int]bool)
+	for _, v := range strings.Split(tok.value, ",") {
+		if strings.EqualFold(strings.TrimSpace(v), "none") {
+			ids[0] = true
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, p.errorf(tok, "invalid task ID %q", v)
+		}
+		ids[id] = true
+	}
+
+	match := func(t *task.Task, env *Env) bool { return ids[get(t)] }
+	switch modifier {
+	case "", "is":
+		return match, nil
+	case "not":
+		return negate(match), nil
+	default:
+		name, _, _ := strings.Cut(tok.field, ".")
+		return nil, p.errorf(tok, "unsupported modifier %q for %s (use is or not)", modifier, name)
+	}
+}
+
+func (p *parser) dateMatcher(tok token, field, modifier string) (func(t *task.Task, env *Env) bool, error) {
+	get := func(t *task.Task) *time.Time {
+		switch field {
+		case "created":
+			return &t.CreatedAt
+		case "updated":
+			return &t.UpdatedAt
+		case "due":
+			return t.Due
+		default:
+			return t.CompletedAt
+		}
+	}
+
+	if strings.EqualFold(tok.value, "none") {
+		isNone := func(t *task.Task, env *Env) bool { return get(t) == nil }
+		switch modifier {
+		case "", "is":
+			return isNone, nil
+		case "not":
+			return negate(isNone), nil
+		default:
+			return nil, p.errorf(tok, "%s.%s cannot be compared with none", field, modifier)
+		}
+	}
+
+	when, err := dates.Parse(tok.value, p.now)
+	if err != nil {
+		return nil, p.errorf(tok, "%v", err)
+	}
+
+	sameDay := func(t *task.Task, env *Env) bool { d := get(t); return d != nil && dates.SameDay(*d, when) }
+	switch modifier {
+	case "", "is":
+		return sameDay, nil
+	case "not":
+		return negate(sameDay), nil
+	case "before":
+		return func(t *task.Task, env *Env) bool { d := get(t); return d != nil && d.Before(when) }, nil
+	case "after":
+		return func(t *task.Task, env *Env) bool { d := get(t); return d != nil && d.After(when) }, nil
+	default:
+		return nil, p.errorf(tok, "unsupported modifier %q for %s (use before, after, is or not)", modifier, field)
+	}
+}
+
+func negate(match func(t *task.Task, env *Env) bool) func(t *task.Task, env *Env) bool {
+	return func(t *task.Task, env *Env) bool { return !match(t, env) }
+}
+
+func fieldNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, f := range fieldAliases {
+		if !seen[f] {
+			seen[f] = true
+			names = append(names, f)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func virtualTagNames() []string {
+	var names []string
+	for name := range VirtualTags {
+		names = append(names, "+"+name)
+	}
+	sort.Strings(names)
+	return names
+}
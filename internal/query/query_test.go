@@ -0,0 +1,111 @@
+package query
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// testTasks returns tasks covering the fields and states filters look at,
+// relative to testNow (Wednesday 2026-03-04 10:30 UTC)
+func testTasks() []*task.Task {
+	at := func(year int, month time.Month, day, hour, min int) *time.Time {
+		t := time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+		return &t
+	}
+	// Date-only due dates are stored at the end of the day
+	day := func(month time.Month, day int) *time.Time {
+		t := time.Date(2026, month, day, 23, 59, 59, 0, time.UTC)
+		return &t
+	}
+	created := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	return []*task.Task{
+		{ID: 1, Description: "Deploy the web app", Status: task.StatusTodo, Priority: task.PriorityHigh, Tags: []string{"work", "urgent"}, Project: "team.web", Due: day(3, 4), CreatedAt: created},
+		{ID: 2, Description: "Write release notes", Status: task.StatusInProgress, Priority: task.PriorityMedium, Tags: []string{"work"}, Project: "team", Due: day(3, 2), DependsOn: []int{1}, CreatedAt: created.AddDate(0, 1, 0)},
+		{ID: 3, Description: "Buy milk", Status: task.StatusCompleted, Priority: task.PriorityLow, Tags: []string{"home"}, Due: at(2026, 3, 1, 12, 0), CompletedAt: at(2026, 3, 1, 18, 0), CreatedAt: created},
+		{ID: 4, Description: "Water plants", Status: task.StatusPending, Priority: task.PriorityLow, Recur: "weekly", ParentID: 3, Due: day(3, 5), CreatedAt: created.AddDate(0, 0, 40)},
+		{ID: 5, Description: "Read a book", Status: task.StatusTodo, Priority: task.PriorityMedium, CreatedAt: created},
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   []int
+	}{
+		// and, or, not and parentheses
+		{"", []int{1, 2, 3, 4, 5}},
+		{"+work", []int{1, 2}},
+		{"+work priority:high", []int{1}},
+		{"+work and priority:high", []int{1}},
+		{"+home or +urgent", []int{1, 3}},
+		{"+home or +work priority:medium", []int{2, 3}},
+		{"(+home or +work) priority:medium", []int{2}},
+		{"not +work", []int{3, 4, 5}},
+		{"!+work !+home", []int{4, 5}},
+		{"-work", []int{3, 4, 5}},
+		{"not (+work or priority:low)", []int{5}},
+
+		// fields and modifiers
+		{"deploy", []int{1}},
+		{`"release notes"`, []int{2}},
+		{"desc~MILK", []int{3}},
+		{"status:todo,pending", []int{1, 4, 5}},
+		{"status.not:completed", []int{1, 2, 4, 5}},
+		{"project:team", []int{1, 2}},
+		{"project.is:team", []int{2}},
+		{"project~web", []int{1}},
+		{"id:1,3", []int{1, 3}},
+		{"id.not:1,3", []int{2, 4, 5}},
+		{"parent:3", []int{4}},
+		{"parent:none", []int{1, 2, 3, 5}},
+		{"tag:home", []int{3}},
+		{"tag.not:work", []int{3, 4, 5}},
+		{"recur:weekly", []int{4}},
+
+		// virtual tags
+		{"+OPEN", []int{1, 2, 4, 5}},
+		{"+COMPLETED", []int{3}},
+		{"+OVERDUE", []int{2}},
+		{"+DUETODAY", []int{1}},
+		{"+DUE", []int{1, 2, 3, 4}},
+		{"+RECURRING", []int{4}},
+		{"+TAGGED", []int{1, 2, 3}},
+		{"+BLOCKED", []int{2}},
+		{"+BLOCKING", []int{1}},
+		{"-BLOCKED +work", []int{1}},
+
+		// dates
+		{"due:today", []int{1}},
+		{"due:tomorrow", []int{4}},
+		{"due:2026-03-02", []int{2}},
+		{"due.not:today", []int{2, 3, 4, 5}},
+		{"due.before:today", []int{2, 3}},
+		{"due.before:now", []int{2, 3}},
+		{"due.after:now", []int{1, 4}},
+		{"due.before:eow and due.after:yesterday", []int{1, 4}},
+		{"due:none", []int{5}},
+		{"due.not:none", []int{1, 2, 3, 4}},
+		{"completed:2026-03-01", []int{3}},
+		{"created.after:2026-02-01", []int{2, 4}},
+		{"entry.before:2026-01-11", []int{1, 3, 5}},
+	}
+
+	tasks := testTasks()
+	for _, tt := range tests {
+		q, err := Parse(tt.filter, testNow)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.filter, err)
+			continue
+		}
+		var got []int
+		for _, task := range q.Filter(tasks) {
+			got = append(got, task.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s matches %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
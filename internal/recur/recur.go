@@ -0,0 +1,400 @@
+// Package recur parses recurrence rules and computes the next occurrence of a
+// recurring task.
+//
+// Two notations are accepted. The short form:
+//
+//	daily, weekly, monthly, yearly
+//	weekly:mon,thu     every Monday and Thursday
+//	monthly:15         the 15th of every month (-1 is the last day)
+//	every:3d           every 3 days (units d, w, m, y)
+//
+// and a subset of RFC 5545 RRULE, with or without the "RRULE:" prefix:
+//
+//	FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH
+//	FREQ=MONTHLY;BYMONTHDAY=1,15;UNTIL=20271231
+package recur
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the base period of a rule
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// Rule describes when a recurring task repeats
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Until      *time.Time
+}
+
+var shortDays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+var rruleDays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// Parse parses a rule in either the short or the RRULE notation
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("empty recurrence rule")
+	}
+
+	var (
+		r   *Rule
+		err error
+	)
+	upper := strings.ToUpper(s)
+	if strings.HasPrefix(upper, "RRULE:") || strings.Contains(upper, "FREQ=") {
+		r, err = parseRRule(strings.TrimPrefix(upper, "RRULE:"))
+	} else {
+		r, err = parseShort(strings.ToLower(s))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid recurrence rule %q: %w", s, err)
+	}
+
+	sort.Slice(r.ByDay, func(i, j int) bool { return r.ByDay[i] < r.ByDay[j] })
+	sort.Ints(r.ByMonthDay)
+	return r, nil
+}
+
+func parseShort(s string) (*Rule, error) {
+	name, arg, hasArg := strings.Cut(s, ":")
+	r := &Rule{Interval: 1}
+
+	switch name {
+	case "daily":
+		r.Freq = Daily
+	case "weekly":
+		r.Freq = Weekly
+		if hasArg {
+			for _, d := range strings.Split(arg, ",") {
+				wd, ok := shortDays[strings.TrimSpace(d)]
+				if !ok {
+					return nil, fmt.Errorf("unknown weekday %q", d)
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+			hasArg = false
+		}
+	case "monthly":
+		r.Freq = Monthly
+		if hasArg {
+			days, err := parseMonthDays(strings.Split(arg, ","))
+			if err != nil {
+				return nil, err
+			}
+			r.ByMonthDay = days
+			hasArg = false
+		}
+	case "yearly":
+		r.Freq = Yearly
+	case "every":
+		if len(arg) < 2 {
+			return nil, fmt.Errorf("expected an interval such as every:3d")
+		}
+		n, err := strconv.Atoi(arg[:len(arg)-1])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid interval %q", arg)
+		}
+		r.Interval = n
+		switch arg[len(arg)-1] {
+		case 'd':
+			r.Freq = Daily
+		case 'w':
+			r.Freq = Weekly
+		case 'm':
+			r.Freq = Monthly
+		case 'y':
+			r.Freq = Yearly
+		default:
+			return nil, fmt.Errorf("unknown interval unit in %q (use d, w, m or y)", arg)
+		}
+		hasArg = false
+	default:
+		return nil, fmt.Errorf("unknown frequency %q (use daily, weekly, monthly, yearly or every:<n><unit>)", name)
+	}
+
+	if hasArg {
+		return nil, fmt.Errorf("%s does not take arguments", name)
+	}
+	return r, nil
+}
+
+func parseRRule(s string) (*Rule, error) {
+	r := &Rule{Interval: 1}
+
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed part %q", part)
+		}
+
+		switch key {
+		case "FREQ":
+			switch Frequency(value) {
+			case Daily, Weekly, Monthly, Yearly:
+				r.Freq = Frequency(value)
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, d := range strings.Split(value, ",") {
+				wd, ok := rruleDays[d]
+				if !ok {
+					return nil, fmt.Errorf("unsupported BYDAY value %q", d)
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			days, err := parseMonthDays(strings.Split(value, ","))
+			if err != nil {
+				return nil, err
+			}
+			r.ByMonthDay = days
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = &until
+		default:
+			return nil, fmt.Errorf("unsupported RRULE part %s", key)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, fmt.Errorf("missing FREQ")
+	}
+	if len(r.ByDay) > 0 && r.Freq != Weekly {
+		return nil, fmt.Errorf("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq != Monthly {
+		return nil, fmt.Errorf("BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	return r, nil
+}
+
+func parseMonthDays(values []string) ([]int, error) {
+	var days []int
+	for _, v := range values {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n == 0 || n < -31 || n > 31 {
+			return nil, fmt.Errorf("invalid day of month %q", v)
+		}
+		days = append(days, n)
+	}
+	return days, nil
+}
+
+// parseUntil parses an UNTIL date or date-time. Date-times ending in Z are in
+// UTC, as in RFC 5545; the others are local.
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation("20060102T150405Z", value, time.UTC); err == nil {
+		return t.Local(), nil
+	}
+	for _, layout := range []string{"20060102T150405", "20060102"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			if layout == "20060102" {
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q", value)
+}
+
+// Next returns the first occurrence strictly after the day of from, keeping
+// from's time of day. It returns false once the rule's UNTIL has passed.
+func (r *Rule) Next(from time.Time) (time.Time, bool) {
+	return r.NextFrom(from, from)
+}
+
+// NextFrom is Next for a series whose first occurrence was anchor. Monthly
+// rules without BYMONTHDAY and yearly rules keep the day of month of anchor,
+// so a series starting on Jan 31 falls on Feb 28 and then on Mar 31 again.
+func (r *Rule) NextFrom(anchor, from time.Time) (time.Time, bool) {
+	var next time.Time
+
+	switch r.Freq {
+	case Daily:
+		next = from.AddDate(0, 0, r.Interval)
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			next = from.AddDate(0, 0, 7*r.Interval)
+			break
+		}
+		// Walk forward day by day, only accepting days in weeks that are a
+		// multiple of the interval away from from's week.
+		start := startOfWeek(from)
+		for i := 1; i <= 7*r.Interval+7; i++ {
+			d := from.AddDate(0, 0, i)
+			weeks := int(startOfWeek(d).Sub(start).Hours()+12) / (24 * 7)
+			if weeks%r.Interval == 0 && r.hasDay(d.Weekday()) {
+				next = d
+				break
+			}
+		}
+	case Monthly:
+		if len(r.ByMonthDay) == 0 {
+			next = stepMonths(anchor, from, r.Interval)
+			break
+		}
+		for m := 0; next.IsZero() && m <= 12*r.Interval; m += r.Interval {
+			first := time.Date(from.Year(), from.Month()+time.Month(m), 1,
+				from.Hour(), from.Minute(), from.Second(), 0, from.Location())
+			for _, day := range r.monthDays(first) {
+				d := first.AddDate(0, 0, day-1)
+				if d.After(from) && !sameDay(d, from) {
+					next = d
+					break
+				}
+			}
+		}
+	case Yearly:
+		next = stepMonths(anchor, from, 12*r.Interval)
+	}
+
+	if next.IsZero() || (r.Until != nil && next.After(*r.Until)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// Matches reports whether day is an occurrence of the rule on its own, which
+// is used to pick the first occurrence of a new series
+func (r *Rule) Matches(day time.Time) bool {
+	switch {
+	case len(r.ByDay) > 0:
+		return r.hasDay(day.Weekday())
+	case len(r.ByMonthDay) > 0:
+		first := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+		for _, d := range r.monthDays(first) {
+			if d == day.Day() {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// String returns the rule in RRULE notation
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		var days []string
+		for _, wd := range r.ByDay {
+			days = append(days, strings.ToUpper(wd.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		var days []string
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	return strings.Join(parts, ";")
+}
+
+func (r *Rule) hasDay(wd time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// monthDays resolves ByMonthDay (including negative days counted from the
+// end) to the sorted days that exist in the month starting at first
+func (r *Rule) monthDays(first time.Time) []int {
+	last := first.AddDate(0, 1, -1).Day()
+	var days []int
+	for _, d := range r.ByMonthDay {
+		if d < 0 {
+			d = last + d + 1
+		}
+		if d >= 1 && d <= last {
+			days = append(days, d)
+		}
+	}
+	sort.Ints(days)
+	return days
+}
+
+// addMonths adds n months, clamping to the last day of the target month
+// instead of overflowing into the next one (Jan 31 + 1 month = Feb 28)
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// stepMonths returns the first day after the day of from that is a multiple
+// of step months from anchor, on anchor's day of month (clamped as by
+// addMonths) and at from's time of day
+func stepMonths(anchor, from time.Time, step int) time.Time {
+	base := time.Date(anchor.Year(), anchor.Month(), anchor.Day(),
+		from.Hour(), from.Minute(), from.Second(), 0, from.Location())
+	months := (from.Year()-base.Year())*12 + int(from.Month()-base.Month())
+	n := max(months/step*step, 0)
+	for {
+		if next := addMonths(base, n); next.After(from) && !sameDay(next, from) {
+			return next
+		}
+		n += step
+	}
+}
+
+func startOfWeek(t time.Time) time.Time {
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return d.AddDate(0, 0, -int(d.Weekday()))
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,66 @@
+package recur
+
+import (
+	"testing"
+	"time"
+)
+
+// inZone runs the test with time.Local set to a zone east of UTC
+func inZone(t *testing.T) *time.Location {
+	t.Helper()
+	loc := time.FixedZone("UTC+7", 7*60*60)
+	saved := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = saved })
+	return loc
+}
+
+func TestParseUntil(t *testing.T) {
+	loc := inZone(t)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"20270301T120000Z", time.Date(2027, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"20270301T120000", time.Date(2027, 3, 1, 12, 0, 0, 0, loc)},
+		{"20270301", time.Date(2027, 3, 1, 23, 59, 59, 0, loc)},
+	}
+	for _, tt := range tests {
+		r, err := Parse("FREQ=DAILY;UNTIL=" + tt.value)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.value, err)
+		}
+		if !r.Until.Equal(tt.want) {
+			t.Errorf("UNTIL=%s parsed as %v, want %v", tt.value, r.Until, tt.want)
+		}
+	}
+}
+
+func TestNextFromKeepsAnchorDay(t *testing.T) {
+	at := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 9, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		rule         string
+		anchor, from time.Time
+		want         time.Time
+	}{
+		{"monthly", at(2026, 1, 31), at(2026, 1, 31), at(2026, 2, 28)},
+		{"monthly", at(2026, 1, 31), at(2026, 2, 28), at(2026, 3, 31)},
+		{"monthly", at(2026, 1, 31), at(2026, 4, 30), at(2026, 5, 31)},
+		{"FREQ=MONTHLY;INTERVAL=2", at(2026, 1, 31), at(2026, 3, 31), at(2026, 5, 31)},
+		{"yearly", at(2028, 2, 29), at(2029, 2, 28), at(2030, 2, 28)},
+		{"yearly", at(2028, 2, 29), at(2031, 2, 28), at(2032, 2, 29)},
+	}
+	for _, tt := range tests {
+		r, err := Parse(tt.rule)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.rule, err)
+		}
+		got, ok := r.NextFrom(tt.anchor, tt.from)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("%s from %s (anchor %s) = %s, want %s", tt.rule,
+				tt.from.Format("2006-01-02"), tt.anchor.Format("2006-01-02"), got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Package report renders status reports of tasks as Markdown or HTML
+// documents, for status mails and wikis.
+//
+// Documents are rendered from templates embedded in the binary. A file of the
+// same name in the template directory, such as report.md.tmpl or
+// report.html.tmpl, is used instead. Templates get a *Data; see the embedded
+// ones for an example.
+package report
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// Formats of the rendered documents
+const (
+	Markdown = "md"
+	HTML     = "html"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// Data is what report templates render
+type Data struct {
+	Title     string
+	Since     time.Time
+	Generated time.Time
+	Summary   task.Summary
+	// Progress is the share of completed tasks in percent
+	Progress int
+	// Sections are, in order: completed since Since, overdue, in progress,
+	// todo and pending. Each task appears in one section at most, so overdue
+	// tasks are not repeated under their status.
+	Sections []Section
+}
+
+// Section is a part of the report with its tasks grouped by priority
+type Section struct {
+	// Key is completed, overdue, in_progress, todo or pending
+	Key    string
+	Title  string
+	Count  int
+	Groups []Group
+}
+
+// Group is the tasks of one priority within a section
+type Group struct {
+	Priority string
+	Tasks    []*task.Task
+}
+
+// Build collects the report of the tasks for the period from since to now.
+// Deleted tasks are left out.
+func Build(title string, tasks []*task.Task, since, now time.Time) *Data {
+	var kept []*task.Task
+	sections := map[string][]*task.Task{}
+	for _, t := range tasks {
+		if t.Status == task.StatusDeleted {
+			continue
+		}
+		kept = append(kept, t)
+
+		switch {
+		case !t.IsOpen():
+			if t.CompletedAt != nil && !t.CompletedAt.Before(since) {
+				sections["completed"] = append(sections["completed"], t)
+			}
+		case t.IsOverdue(now):
+			sections["overdue"] = append(sections["overdue"], t)
+		default:
+			sections[t.Status] = append(sections[t.Status], t)
+		}
+	}
+
+	d := &Data{Title: title, Since: since, Generated: now, Summary: task.Summarize(kept)}
+	d.Progress, _ = d.Summary.Progress()
+	for _, s := range []struct{ key, title string }{
+		{"completed", "Completed"},
+		{"overdue", "Overdue"},
+		{task.StatusInProgress, "In Progress"},
+		{task.StatusTodo, "To Do"},
+		{task.StatusPending, "Pending"},
+	} {
+		d.Sections = append(d.Sections, newSection(s.key, s.title, sections[s.key]))
+	}
+	return d
+}
+
+// newSection groups the tasks by priority, highest first. Completed tasks are
+// ordered by when they were completed, others by due date and then ID.
+func newSection(key, title string, tasks []*task.Task) Section {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if key == "completed" {
+			return a.CompletedAt.Before(*b.CompletedAt)
+		}
+		switch {
+		case a.Due != nil && b.Due != nil && !a.Due.Equal(*b.Due):
+			return a.Due.Before(*b.Due)
+		case (a.Due == nil) != (b.Due == nil):
+			return a.Due != nil
+		}
+		return a.ID < b.ID
+	})
+
+	s := Section{Key: key, Title: title, Count: len(tasks)}
+	for _, p := range []string{task.PriorityHigh, task.PriorityMedium, task.PriorityLow} {
+		g := Group{Priority: p}
+		for _, t := range tasks {
+			if t.Priority == p {
+				g.Tasks = append(g.Tasks, t)
+			}
+		}
+		if len(g.Tasks) > 0 {
+			s.Groups = append(s.Groups, g)
+		}
+	}
+	return s
+}
+
+// funcs are the helpers available to report templates
+var funcs = map[string]any{
+	"date": func(layout string, t any) string {
+		switch v := t.(type) {
+		case time.Time:
+			if !v.IsZero() {
+				return v.Local().Format(layout)
+			}
+		case *time.Time:
+			if v != nil {
+				return v.Local().Format(layout)
+			}
+		}
+		return ""
+	},
+	"title": func(s string) string {
+		if s == "" {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + s[1:]
+	},
+	"md": markdownEscaper.Replace,
+	"tags": func(tags []string) string {
+		var formatted []string
+		for _, tag := range tags {
+			formatted = append(formatted, "#"+tag)
+		}
+		return strings.Join(formatted, " ")
+	},
+}
+
+// markdownEscaper escapes the characters of task text that Markdown would
+// take for formatting
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "|", `\|`,
+)
+
+// executor is what text/template and html/template templates have in common
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// Render writes the report in the format, using the template in dir when
+// there is one
+func Render(w io.Writer, format, dir string, d *Data) error {
+	if format != Markdown && format != HTML {
+		return fmt.Errorf("invalid report format: %s. Valid formats are: md, html", format)
+	}
+
+	name := "report." + format + ".tmpl"
+	text, err := readTemplate(dir, name)
+	if err != nil {
+		return err
+	}
+
+	var tmpl executor
+	if format == HTML {
+		tmpl, err = htmltemplate.New(name).Funcs(funcs).Parse(text)
+	} else {
+		tmpl, err = texttemplate.New(name).Funcs(funcs).Parse(text)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid report template: %w", err)
+	}
+
+	if err := tmpl.Execute(w, d); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+// readTemplate returns the template from dir, or else the embedded one
+func readTemplate(dir, name string) (string, error) {
+	if dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return string(data), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("failed to read report template: %w", err)
+		}
+	}
+
+	data, err := templates.ReadFile("templates/" + name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read report template: %w", err)
+	}
+	return string(data), nil
+}
+
+// Template returns the embedded template of the format, as a starting point
+// for a custom one
+func Template(format string) (string, error) {
+	return readTemplate("", "report."+format+".tmpl")
+}
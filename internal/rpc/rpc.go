@@ -0,0 +1,550 @@
+// Package rpc serves a task store over line-delimited JSON-RPC 2.0, for editor
+// integrations that talk to taskman over stdin and stdout.
+//
+// Every request and response is one line of JSON. Batches are supported.
+// Methods take their parameters by name:
+//
+//	add        {description, priority, status, project, tags, due, recur,
+//	           parent_id, depends_on}                  → task
+//	getAll     {filter}                               → [task]
+//	getById    {id}                                   → task
+//	update     {id, ...fields of add}                 → task
+//	delete     {id}                                   → the deleted task
+//	complete   {id}                                   → task
+//	addTag     {id, tag}                              → task
+//	removeTag  {id, tag}                              → task
+//	subscribe  {}                                     → {subscription}
+//	unsubscribe {subscription}                        → true
+//
+// After subscribe, the server sends the notification
+//
+//	{"jsonrpc": "2.0", "method": "tasksChanged", "params": {"subscription": 1}}
+//
+// whenever the task store changes, through this connection or any other
+// taskman process.
+//
+// Errors carry one of the codes below; the message is meant for people.
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vkhangstack/taskman/internal/query"
+	"github.com/vkhangstack/taskman/internal/task"
+	"github.com/vkhangstack/taskman/internal/watch"
+)
+
+// Error codes. The first five are defined by JSON-RPC 2.0, the others by
+// taskman.
+const (
+	CodeParseError     = -32700 // the line is not valid JSON
+	CodeInvalidRequest = -32600 // the JSON is not a request
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602 // missing, malformed or unknown parameters
+	CodeInternalError  = -32603 // the store failed
+	CodeNotFound       = -32001 // there is no task with the ID, or no such tag or subscription
+	CodeInvalidTask    = -32002 // the task would be invalid, e.g. an unknown priority or a dependency cycle
+	CodeInvalidFilter  = -32003 // the filter expression does not parse
+)
+
+// Error is a JSON-RPC error object
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// errorf returns an Error with the code
+func errorf(code int, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// request is a JSON-RPC request, or a notification when ID is absent
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// response is a JSON-RPC response
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// notification is a message from the server that expects no response
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+// Options configure the server
+type Options struct {
+	// Validate checks tasks before they are created or changed
+	Validate func(t *task.Task) error
+	// WatchPath is the file of the store, watched for subscriptions
+	WatchPath string
+	// Log receives the errors of the watcher, one per line; nil discards them
+	Log io.Writer
+}
+
+// Server answers requests on a task store
+type Server struct {
+	store   task.Store
+	opts    Options
+	methods map[string]func(params json.RawMessage) (any, error)
+
+	// mu guards the output, which notifications share with responses, and
+	// the subscriptions
+	mu      sync.Mutex
+	enc     *json.Encoder
+	subs    map[int]bool
+	nextSub int
+	watcher *watch.Watcher
+}
+
+// New returns a server for the store
+func New(store task.Store, opts Options) *Server {
+	s := &Server{store: store, opts: opts, subs: make(map[int]bool)}
+	s.methods = map[string]func(json.RawMessage) (any, error){
+		"add":         s.add,
+		"getAll":      s.getAll,
+		"getById":     s.getByID,
+		"update":      s.update,
+		"delete":      s.delete,
+		"complete":    s.complete,
+		"addTag":      s.addTag,
+		"removeTag":   s.removeTag,
+		"subscribe":   s.subscribe,
+		"unsubscribe": s.unsubscribe,
+	}
+	return s
+}
+
+// Serve reads requests from r and writes responses to w until r ends
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.enc = json.NewEncoder(w)
+	defer s.stopWatching()
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if reply := s.handleLine(line); reply != nil {
+				if werr := s.write(reply); werr != nil {
+					return werr
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read request: %w", err)
+		}
+	}
+}
+
+// handleLine answers a request or a batch, and returns nil when there is
+// nothing to send back
+func (s *Server) handleLine(line []byte) any {
+	if line[0] != '[' {
+		req, rpcErr := decodeRequest(line)
+		if rpcErr != nil {
+			return errorResponse(nil, rpcErr)
+		}
+		if res := s.handle(req); res != nil {
+			return res
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(line, &batch); err != nil {
+		return errorResponse(nil, errorf(CodeParseError, "parse error: %v", err))
+	}
+	if len(batch) == 0 {
+		return errorResponse(nil, errorf(CodeInvalidRequest, "empty batch"))
+	}
+	var replies []*response
+	for _, raw := range batch {
+		req, rpcErr := decodeRequest(raw)
+		if rpcErr != nil {
+			replies = append(replies, errorResponse(nil, rpcErr))
+			continue
+		}
+		if res := s.handle(req); res != nil {
+			replies = append(replies, res)
+		}
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+	return replies
+}
+
+// decodeRequest reads a request. Text that is not JSON is a parse error,
+// and JSON that is not a request object, such as 1 or a method that is not a
+// string, an invalid request.
+func decodeRequest(raw []byte) (request, *Error) {
+	var req request
+	err := json.Unmarshal(raw, &req)
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return req, errorf(CodeParseError, "parse error: %v", err)
+	case err != nil:
+		return req, errorf(CodeInvalidRequest, "invalid request: %v", err)
+	}
+	return req, nil
+}
+
+// handle calls the method of a request. Notifications get no response.
+func (s *Server) handle(req request) *response {
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, errorf(CodeInvalidRequest, `invalid request: jsonrpc must be "2.0" and method set`))
+	}
+
+	method, ok := s.methods[req.Method]
+	var result any
+	var err error
+	if ok {
+		result, err = method(req.Params)
+	} else {
+		err = errorf(CodeMethodNotFound, "method not found: %s", req.Method)
+	}
+
+	if req.ID == nil {
+		return nil
+	}
+	if err != nil {
+		return errorResponse(req.ID, toError(err))
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+// write sends one message
+func (s *Server) write(v any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write response: %w", err)
+	}
+	return nil
+}
+
+// errorResponse returns the response for an error. Requests whose ID could
+// not be read get a null ID.
+func errorResponse(id json.RawMessage, err *Error) *response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: "2.0", ID: id, Error: err}
+}
+
+// toError maps the errors of the store onto error codes
+func toError(err error) *Error {
+	var rpcErr *Error
+	switch {
+	case errors.As(err, &rpcErr):
+		return rpcErr
+	case errors.Is(err, task.ErrNotFound):
+		return errorf(CodeNotFound, "%v", err)
+	default:
+		return errorf(CodeInternalError, "%v", err)
+	}
+}
+
+// decodeParams reads named parameters, which may be left out when there are
+// none
+func decodeParams(raw json.RawMessage, v any) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		raw = json.RawMessage("{}")
+	}
+	if raw[0] != '{' {
+		return errorf(CodeInvalidParams, "invalid params: params must be an object")
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errorf(CodeInvalidParams, "invalid params: %v", err)
+	}
+	return nil
+}
+
+// idParams are the parameters of the methods on a single task
+type idParams struct {
+	ID int `json:"id"`
+}
+
+func (s *Server) add(raw json.RawMessage) (any, error) {
+	var changes task.Changes
+	if err := decodeParams(raw, &changes); err != nil {
+		return nil, err
+	}
+
+	t := &task.Task{Status: task.StatusTodo, Priority: task.PriorityMedium, Tags: []string{}}
+	if err := s.apply(t, changes); err != nil {
+		return nil, err
+	}
+	id, err := s.store.Add(t)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.GetByID(id)
+}
+
+func (s *Server) getAll(raw json.RawMessage) (any, error) {
+	var p struct {
+		Filter string `json:"filter"`
+	}
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	q, err := query.Parse(p.Filter, time.Now())
+	if err != nil {
+		return nil, errorf(CodeInvalidFilter, "%v", err)
+	}
+
+	tasks, err := s.store.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	tasks = q.Filter(tasks)
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	if tasks == nil {
+		tasks = []*task.Task{}
+	}
+	return tasks, nil
+}
+
+func (s *Server) getByID(raw json.RawMessage) (any, error) {
+	var p idParams
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	return s.store.GetByID(p.ID)
+}
+
+func (s *Server) update(raw json.RawMessage) (any, error) {
+	var p struct {
+		ID int `json:"id"`
+		task.Changes
+	}
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	t, err := s.store.GetByID(p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Completing goes through the store, which starts the next occurrence of
+	// recurring tasks
+	complete := p.Status != nil && *p.Status == task.StatusCompleted
+	if complete {
+		p.Status = nil
+	}
+	if err := s.apply(t, p.Changes); err != nil {
+		return nil, err
+	}
+	if err := s.store.Update(t); err != nil {
+		return nil, err
+	}
+	if complete {
+		if err := s.store.Complete(t.ID); err != nil {
+			return nil, err
+		}
+	}
+	return s.store.GetByID(t.ID)
+}
+
+func (s *Server) delete(raw json.RawMessage) (any, error) {
+	var p idParams
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	t, err := s.store.GetByID(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Delete(p.ID); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *Server) complete(raw json.RawMessage) (any, error) {
+	var p idParams
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	if err := s.store.Complete(p.ID); err != nil {
+		return nil, err
+	}
+	return s.store.GetByID(p.ID)
+}
+
+// tagParams are the parameters of addTag and removeTag
+type tagParams struct {
+	ID  int    `json:"id"`
+	Tag string `json:"tag"`
+}
+
+func (s *Server) addTag(raw json.RawMessage) (any, error) {
+	var p tagParams
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	if p.Tag = strings.TrimSpace(p.Tag); p.Tag == "" {
+		return nil, errorf(CodeInvalidParams, "tag cannot be empty")
+	}
+	if err := s.store.AddTag(p.ID, p.Tag); err != nil {
+		return nil, err
+	}
+	return s.store.GetByID(p.ID)
+}
+
+func (s *Server) removeTag(raw json.RawMessage) (any, error) {
+	var p tagParams
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	t, err := s.store.GetByID(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !t.HasTag(p.Tag) {
+		return nil, errorf(CodeNotFound, "tag %q not found on task with ID %d", p.Tag, p.ID)
+	}
+	if err := s.store.RemoveTag(p.ID, p.Tag); err != nil {
+		return nil, err
+	}
+	return s.store.GetByID(p.ID)
+}
+
+// apply sets the changed fields on the task and validates it together with
+// the tasks it relates to
+func (s *Server) apply(t *task.Task, changes task.Changes) error {
+	if err := changes.Apply(t, time.Now()); err != nil {
+		return errorf(CodeInvalidTask, "%v", err)
+	}
+	if s.opts.Validate != nil {
+		if err := s.opts.Validate(t); err != nil {
+			return errorf(CodeInvalidTask, "%v", err)
+		}
+	}
+
+	all, err := s.store.GetAll()
+	if err != nil {
+		return err
+	}
+	if err := task.CheckRelationsOf(t, all); err != nil {
+		return errorf(CodeInvalidTask, "%v", err)
+	}
+	return nil
+}
+
+func (s *Server) subscribe(raw json.RawMessage) (any, error) {
+	if err := decodeParams(raw, &struct{}{}); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.watcher == nil {
+		if s.opts.WatchPath == "" {
+			return nil, errorf(CodeInternalError, "the store cannot be watched")
+		}
+		w, err := watch.New(s.opts.WatchPath, watch.DefaultDelay)
+		if err != nil {
+			return nil, err
+		}
+		s.watcher = w
+		go s.notify(w)
+	}
+
+	s.nextSub++
+	s.subs[s.nextSub] = true
+	return map[string]int{"subscription": s.nextSub}, nil
+}
+
+func (s *Server) unsubscribe(raw json.RawMessage) (any, error) {
+	var p struct {
+		Subscription int `json:"subscription"`
+	}
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.subs[p.Subscription] {
+		return nil, errorf(CodeNotFound, "subscription %d not found", p.Subscription)
+	}
+	delete(s.subs, p.Subscription)
+	return true, nil
+}
+
+// notify sends tasksChanged to every subscription when the store changes.
+// Errors of the watcher, which must be read for it to keep going, are logged
+// and followed by tasksChanged too, since changes may have been missed.
+func (s *Server) notify(w *watch.Watcher) {
+	errs := w.Errors
+	for {
+		select {
+		case _, ok := <-w.C:
+			if !ok {
+				return
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if s.opts.Log != nil {
+				fmt.Fprintf(s.opts.Log, "watch error: %v\n", err)
+			}
+		}
+
+		s.mu.Lock()
+		ids := make([]int, 0, len(s.subs))
+		for id := range s.subs {
+			ids = append(ids, id)
+		}
+		s.mu.Unlock()
+
+		sort.Ints(ids)
+		for _, id := range ids {
+			msg := notification{JSONRPC: "2.0", Method: "tasksChanged", Params: map[string]int{"subscription": id}}
+			if err := s.write(msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// stopWatching closes the watcher of the subscriptions, if any
+func (s *Server) stopWatching() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.watcher != nil {
+		s.watcher.Close()
+	}
+}
@@ -0,0 +1,217 @@
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// client talks to a server over pipes, one line at a time
+type client struct {
+	t   *testing.T
+	in  *io.PipeWriter
+	out *bufio.Scanner
+}
+
+// newClient serves a new store and returns a client for it
+func newClient(t *testing.T) *client {
+	t.Helper()
+	store, err := task.NewFileStoreAt(filepath.Join(t.TempDir(), "tasks.json"))
+	if err != nil {
+		t.Fatalf("NewFileStoreAt: %v", err)
+	}
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- New(store, Options{}).Serve(inR, outW)
+		outW.Close()
+	}()
+	t.Cleanup(func() {
+		inW.Close()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	return &client{t: t, in: inW, out: bufio.NewScanner(outR)}
+}
+
+// send writes a line without waiting for an answer
+func (c *client) send(line string) {
+	c.t.Helper()
+	if _, err := io.WriteString(c.in, line+"\n"); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+}
+
+// call writes a line and decodes the line the server answers with
+func (c *client) call(line string, v any) {
+	c.t.Helper()
+	c.send(line)
+	if !c.out.Scan() {
+		c.t.Fatalf("no answer to %s: %v", line, c.out.Err())
+	}
+	if err := json.Unmarshal(c.out.Bytes(), v); err != nil {
+		c.t.Fatalf("answer %s: %v", c.out.Text(), err)
+	}
+}
+
+// testResponse is a response with the result left to decode
+type testResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *Error          `json:"error"`
+}
+
+func TestCall(t *testing.T) {
+	c := newClient(t)
+
+	var res testResponse
+	c.call(`{"jsonrpc":"2.0","id":1,"method":"add","params":{"description":"Write tests","priority":"high","tags":["work"]}}`, &res)
+	if res.Error != nil || string(res.ID) != "1" || res.JSONRPC != "2.0" {
+		t.Fatalf("add = %+v", res)
+	}
+	var added task.Task
+	if err := json.Unmarshal(res.Result, &added); err != nil {
+		t.Fatal(err)
+	}
+	if added.ID != 1 || added.Description != "Write tests" || added.Priority != task.PriorityHigh || !added.HasTag("work") {
+		t.Errorf("add returned %+v", added)
+	}
+
+	c.call(`{"jsonrpc":"2.0","id":"two","method":"complete","params":{"id":1}}`, &res)
+	var done task.Task
+	if err := json.Unmarshal(res.Result, &done); err != nil || done.Status != task.StatusCompleted || string(res.ID) != `"two"` {
+		t.Errorf("complete = %+v, %s (%v)", res, res.Result, err)
+	}
+}
+
+func TestNotificationsGetNoResponse(t *testing.T) {
+	c := newClient(t)
+
+	// Both the notification that succeeds and the one that fails are quiet,
+	// so the next line answers the getAll
+	c.send(`{"jsonrpc":"2.0","method":"add","params":{"description":"Quiet"}}`)
+	c.send(`{"jsonrpc":"2.0","method":"nothing"}`)
+	var res testResponse
+	c.call(`{"jsonrpc":"2.0","id":7,"method":"getAll"}`, &res)
+	if string(res.ID) != "7" || res.Error != nil {
+		t.Fatalf("getAll = %+v", res)
+	}
+	var tasks []task.Task
+	if err := json.Unmarshal(res.Result, &tasks); err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].Description != "Quiet" {
+		t.Errorf("getAll = %+v, want the task the notification added", tasks)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	c := newClient(t)
+
+	var replies []testResponse
+	c.call(`[`+
+		`{"jsonrpc":"2.0","id":1,"method":"add","params":{"description":"One"}},`+
+		`{"jsonrpc":"2.0","method":"add","params":{"description":"Two"}},`+
+		`1,`+
+		`{"jsonrpc":"2.0","id":2,"method":"getById","params":{"id":9}},`+
+		`{"jsonrpc":"2.0","id":3,"method":"getAll","params":{"filter":"two"}}`+
+		`]`, &replies)
+
+	want := []struct {
+		id   string
+		code int
+	}{{"1", 0}, {"null", CodeInvalidRequest}, {"2", CodeNotFound}, {"3", 0}}
+	if len(replies) != len(want) {
+		t.Fatalf("got %d replies, want %d: %+v", len(replies), len(want), replies)
+	}
+	for i, w := range want {
+		code := 0
+		if replies[i].Error != nil {
+			code = replies[i].Error.Code
+		}
+		if string(replies[i].ID) != w.id || code != w.code {
+			t.Errorf("reply %d = id %s, code %d, want id %s, code %d", i, replies[i].ID, code, w.id, w.code)
+		}
+	}
+	// The requests of a batch run in order
+	var tasks []task.Task
+	if err := json.Unmarshal(replies[3].Result, &tasks); err != nil || len(tasks) != 1 || tasks[0].ID != 2 {
+		t.Errorf("getAll after the batch's adds = %s (%v)", replies[3].Result, err)
+	}
+
+	// A batch of notifications has no answer at all
+	c.send(`[{"jsonrpc":"2.0","method":"add","params":{"description":"Three"}}]`)
+	var res testResponse
+	c.call(`{"jsonrpc":"2.0","id":4,"method":"getById","params":{"id":3}}`, &res)
+	if string(res.ID) != "4" || res.Error != nil {
+		t.Errorf("getById after a batch of notifications = %+v", res)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	c := newClient(t)
+	var res testResponse
+	c.call(`{"jsonrpc":"2.0","id":1,"method":"add","params":{"description":"Tagged","tags":["home"]}}`, &res)
+
+	tests := []struct {
+		name string
+		line string
+		code int
+		msg  string
+	}{
+		{"not JSON", `{"jsonrpc":`, CodeParseError, "parse error"},
+		{"broken batch", `[{"jsonrpc":"2.0"`, CodeParseError, "parse error"},
+		{"number", `1`, CodeInvalidRequest, "invalid request"},
+		{"string", `"add"`, CodeInvalidRequest, "invalid request"},
+		{"null", `null`, CodeInvalidRequest, "invalid request"},
+		{"method type", `{"jsonrpc":"2.0","id":1,"method":1}`, CodeInvalidRequest, "invalid request"},
+		{"version", `{"jsonrpc":"1.0","id":1,"method":"getAll"}`, CodeInvalidRequest, `jsonrpc must be "2.0"`},
+		{"no method", `{"jsonrpc":"2.0","id":1}`, CodeInvalidRequest, `jsonrpc must be "2.0" and method set`},
+		{"empty batch", `[]`, CodeInvalidRequest, "empty batch"},
+		{"method", `{"jsonrpc":"2.0","id":1,"method":"purge"}`, CodeMethodNotFound, "method not found: purge"},
+		{"unknown param", `{"jsonrpc":"2.0","id":1,"method":"getById","params":{"id":1,"uuid":"x"}}`, CodeInvalidParams, `unknown field "uuid"`},
+		{"unknown change", `{"jsonrpc":"2.0","id":1,"method":"update","params":{"id":1,"colour":"red"}}`, CodeInvalidParams, `unknown field "colour"`},
+		{"unknown subscribe param", `{"jsonrpc":"2.0","id":1,"method":"subscribe","params":{"all":true}}`, CodeInvalidParams, `unknown field "all"`},
+		{"param type", `{"jsonrpc":"2.0","id":1,"method":"getById","params":{"id":"1"}}`, CodeInvalidParams, "invalid params"},
+		{"positional params", `{"jsonrpc":"2.0","id":1,"method":"getById","params":[1]}`, CodeInvalidParams, "params must be an object"},
+		{"empty tag", `{"jsonrpc":"2.0","id":1,"method":"addTag","params":{"id":1,"tag":" "}}`, CodeInvalidParams, "tag cannot be empty"},
+		{"no task", `{"jsonrpc":"2.0","id":1,"method":"complete","params":{"id":42}}`, CodeNotFound, "42"},
+		{"no tag", `{"jsonrpc":"2.0","id":1,"method":"removeTag","params":{"id":1,"tag":"work"}}`, CodeNotFound, `tag "work" not found on task with ID 1`},
+		{"no subscription", `{"jsonrpc":"2.0","id":1,"method":"unsubscribe","params":{"subscription":3}}`, CodeNotFound, "subscription 3 not found"},
+		{"cycle", `{"jsonrpc":"2.0","id":1,"method":"update","params":{"id":1,"depends_on":[1]}}`, CodeInvalidTask, "cannot depend on itself"},
+		{"due", `{"jsonrpc":"2.0","id":1,"method":"add","params":{"description":"x","due":"someday"}}`, CodeInvalidTask, "invalid date"},
+		{"filter", `{"jsonrpc":"2.0","id":1,"method":"getAll","params":{"filter":"(+home"}}`, CodeInvalidFilter, "unclosed '('"},
+		// Without a store file to watch, subscriptions cannot work
+		{"unwatched", `{"jsonrpc":"2.0","id":1,"method":"subscribe"}`, CodeInternalError, "the store cannot be watched"},
+	}
+	for _, tt := range tests {
+		var res testResponse
+		c.call(tt.line, &res)
+		if res.Error == nil {
+			t.Errorf("%s: got result %s, want error %d", tt.name, res.Result, tt.code)
+			continue
+		}
+		if res.Error.Code != tt.code || !strings.Contains(res.Error.Message, tt.msg) {
+			t.Errorf("%s: error %d %q, want %d containing %q", tt.name, res.Error.Code, res.Error.Message, tt.code, tt.msg)
+		}
+		if res.Result != nil {
+			t.Errorf("%s: error response has a result %s", tt.name, res.Result)
+		}
+	}
+
+	// Requests that fail do not change the task
+	c.call(`{"jsonrpc":"2.0","id":2,"method":"getById","params":{"id":1}}`, &res)
+	var tk task.Task
+	if err := json.Unmarshal(res.Result, &tk); err != nil || len(tk.DependsOn) != 0 || tk.Status != task.StatusTodo {
+		t.Errorf("task after the errors = %+v (%v)", tk, err)
+	}
+}
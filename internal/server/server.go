@@ -0,0 +1,420 @@
+// Package server exposes a task store as a JSON REST API:
+//
+//	GET    /tasks                  list tasks; ?filter= takes a filter expression,
+//	                               ?sort=, ?limit= and ?offset= work as for list
+//	POST   /tasks                  create a task
+//	GET    /tasks/{id}             get a task
+//	PATCH  /tasks/{id}             change the fields given in the body
+//	DELETE /tasks/{id}             delete a task
+//	POST   /tasks/{id}/complete    complete a task
+//	POST   /tasks/{id}/tags        add the tag {"tag": "..."}
+//	DELETE /tasks/{id}/tags/{tag}  remove a tag
+//
+// Tasks are encoded as in the JSON store. Responses with a single task carry
+// its ETag; PATCH, DELETE and the task actions only go ahead when an If-Match
+// header, if given, names the current ETag, and fail with 412 otherwise.
+//
+// Errors are {"error": {"code": "not_found", "message": "..."}} with the
+// status codes 400, 401, 404, 412 and 500.
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vkhangstack/taskman/internal/query"
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// Options configure the API
+type Options struct {
+	// Token, when set, has to be sent as "Authorization: Bearer <token>"
+	Token string
+	// Validate checks tasks before they are created or changed
+	Validate func(t *task.Task) error
+	// Urgency scores tasks for ?sort=urgency; the default weights are used
+	// when it is nil
+	Urgency func(tasks []*task.Task) (map[int]float64, error)
+}
+
+// Server handles API requests on a store
+type Server struct {
+	store task.Store
+	opts  Options
+	mux   *http.ServeMux
+
+	// mu makes the check of If-Match and the change that follows one step for
+	// requests to this server
+	mu sync.Mutex
+}
+
+// errBadRequest marks errors caused by the request
+var errBadRequest = errors.New("bad request")
+
+// errPrecondition is returned when If-Match does not name the current ETag
+var errPrecondition = errors.New("precondition failed")
+
+// New returns the API handler for the store
+func New(store task.Store, opts Options) *Server {
+	s := &Server{store: store, opts: opts, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /tasks", s.listTasks)
+	s.mux.HandleFunc("POST /tasks", s.createTask)
+	s.mux.HandleFunc("GET /tasks/{id}", s.getTask)
+	s.mux.HandleFunc("PATCH /tasks/{id}", s.patchTask)
+	s.mux.HandleFunc("DELETE /tasks/{id}", s.deleteTask)
+	s.mux.HandleFunc("POST /tasks/{id}/complete", s.completeTask)
+	s.mux.HandleFunc("POST /tasks/{id}/tags", s.addTag)
+	s.mux.HandleFunc("DELETE /tasks/{id}/tags/{tag}", s.removeTag)
+	return s
+}
+
+// ServeHTTP checks the bearer token and dispatches the request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.opts.Token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="taskman"`)
+			writeError(w, http.StatusUnauthorized, "unauthorized", "missing or invalid bearer token")
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) listTasks(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	q, err := query.Parse(params.Get("filter"), time.Now())
+	if err != nil {
+		writeErr(w, fmt.Errorf("%w: %w", errBadRequest, err))
+		return
+	}
+	keys, err := task.ParseSort(params.Get("sort"))
+	if err != nil {
+		writeErr(w, fmt.Errorf("%w: %w", errBadRequest, err))
+		return
+	}
+	limit, err := intParam(params.Get("limit"), "limit")
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	offset, err := intParam(params.Get("offset"), "offset")
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	all, err := s.store.GetAll()
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	urgency := task.UrgencyScores(all, task.DefaultUrgencyWeights(), time.Now())
+	if s.opts.Urgency != nil {
+		if urgency, err = s.opts.Urgency(all); err != nil {
+			writeErr(w, err)
+			return
+		}
+	}
+	tasks := q.Filter(all)
+	task.SortTasks(tasks, keys, urgency)
+
+	total := len(tasks)
+	tasks = tasks[min(offset, total):]
+	if limit > 0 && limit < len(tasks) {
+		tasks = tasks[:limit]
+	}
+	if tasks == nil {
+		tasks = []*task.Task{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"tasks": tasks, "total": total})
+}
+
+func (s *Server) createTask(w http.ResponseWriter, r *http.Request) {
+	var in task.Changes
+	if err := decodeBody(w, r, &in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := &task.Task{Status: task.StatusTodo, Priority: task.PriorityMedium, Tags: []string{}}
+	if err := s.apply(t, in); err != nil {
+		writeErr(w, err)
+		return
+	}
+	id, err := s.store.Add(t)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	created, err := s.store.GetByID(id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/tasks/%d", id))
+	writeTask(w, http.StatusCreated, created)
+}
+
+func (s *Server) getTask(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	t, err := s.store.GetByID(id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	if match := r.Header.Get("If-None-Match"); match != "" && matchesETag(match, etag(t)) {
+		w.Header().Set("ETag", etag(t))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeTask(w, http.StatusOK, t)
+}
+
+func (s *Server) patchTask(w http.ResponseWriter, r *http.Request) {
+	var in task.Changes
+	if err := decodeBody(w, r, &in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	// Completing goes through the store, which starts the next occurrence of
+	// recurring tasks
+	complete := in.Status != nil && *in.Status == task.StatusCompleted
+	if complete {
+		in.Status = nil
+	}
+	s.change(w, r, func(t *task.Task) error {
+		if err := s.apply(t, in); err != nil {
+			return err
+		}
+		if err := s.store.Update(t); err != nil {
+			return err
+		}
+		if complete {
+			return s.store.Complete(t.ID)
+		}
+		return nil
+	})
+}
+
+func (s *Server) deleteTask(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, err := s.store.GetByID(id)
+	if err == nil {
+		err = checkIfMatch(r, t)
+	}
+	if err == nil {
+		err = s.store.Delete(id)
+	}
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) completeTask(w http.ResponseWriter, r *http.Request) {
+	s.change(w, r, func(t *task.Task) error {
+		return s.store.Complete(t.ID)
+	})
+}
+
+func (s *Server) addTag(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Tag string `json:"tag"`
+	}
+	if err := decodeBody(w, r, &in); err != nil {
+		writeErr(w, err)
+		return
+	}
+	tag := strings.TrimSpace(in.Tag)
+	if tag == "" {
+		writeErr(w, fmt.Errorf("%w: tag cannot be empty", errBadRequest))
+		return
+	}
+	s.change(w, r, func(t *task.Task) error {
+		return s.store.AddTag(t.ID, tag)
+	})
+}
+
+func (s *Server) removeTag(w http.ResponseWriter, r *http.Request) {
+	tag := r.PathValue("tag")
+	s.change(w, r, func(t *task.Task) error {
+		if !t.HasTag(tag) {
+			return fmt.Errorf("tag %q %w on task with ID %d", tag, task.ErrNotFound, t.ID)
+		}
+		return s.store.RemoveTag(t.ID, tag)
+	})
+}
+
+// change runs fn on the task of the request once If-Match is checked, and
+// responds with the changed task
+func (s *Server) change(w http.ResponseWriter, r *http.Request, fn func(t *task.Task) error) {
+	id, err := taskID(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, err := s.store.GetByID(id)
+	if err == nil {
+		err = checkIfMatch(r, t)
+	}
+	if err == nil {
+		err = fn(t)
+	}
+	if err == nil {
+		t, err = s.store.GetByID(id)
+	}
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeTask(w, http.StatusOK, t)
+}
+
+// apply sets the fields given in the input on the task and validates it
+// together with the tasks it relates to
+func (s *Server) apply(t *task.Task, in task.Changes) error {
+	if err := in.Apply(t, time.Now()); err != nil {
+		return fmt.Errorf("%w: %w", errBadRequest, err)
+	}
+	if s.opts.Validate != nil {
+		if err := s.opts.Validate(t); err != nil {
+			return fmt.Errorf("%w: %w", errBadRequest, err)
+		}
+	}
+
+	all, err := s.store.GetAll()
+	if err != nil {
+		return err
+	}
+	if err := task.CheckRelationsOf(t, all); err != nil {
+		return fmt.Errorf("%w: %w", errBadRequest, err)
+	}
+	return nil
+}
+
+// etag identifies the current state of a task
+func etag(t *task.Task) string {
+	data, _ := json.Marshal(t)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// matchesETag reports whether an If-Match or If-None-Match header names the
+// ETag, or is *
+func matchesETag(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch returns errPrecondition when the request has an If-Match
+// header that does not name the current ETag of the task
+func checkIfMatch(r *http.Request, t *task.Task) error {
+	header := r.Header.Get("If-Match")
+	if header == "" || matchesETag(header, etag(t)) {
+		return nil
+	}
+	return fmt.Errorf("%w: task with ID %d has changed", errPrecondition, t.ID)
+}
+
+// taskID reads the {id} of the request path
+func taskID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: invalid task ID %q", errBadRequest, r.PathValue("id"))
+	}
+	return id, nil
+}
+
+// intParam reads a non-negative query parameter, which defaults to 0
+func intParam(value, name string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: invalid %s %q", errBadRequest, name, value)
+	}
+	return n, nil
+}
+
+// decodeBody reads a JSON request body, rejecting unknown fields
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%w: invalid JSON body: %w", errBadRequest, err)
+	}
+	return nil
+}
+
+// writeTask responds with the task and its ETag
+func writeTask(w http.ResponseWriter, status int, t *task.Task) {
+	w.Header().Set("ETag", etag(t))
+	writeJSON(w, status, t)
+}
+
+// writeJSON responds with v encoded as JSON
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// writeErr responds with the status code that matches the error
+func writeErr(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, task.ErrNotFound):
+		writeError(w, http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, errBadRequest):
+		writeError(w, http.StatusBadRequest, "bad_request", strings.TrimPrefix(err.Error(), errBadRequest.Error()+": "))
+	case errors.Is(err, errPrecondition):
+		writeError(w, http.StatusPreconditionFailed, "precondition_failed", strings.TrimPrefix(err.Error(), errPrecondition.Error()+": "))
+	default:
+		writeError(w, http.StatusInternalServerError, "internal", err.Error())
+	}
+}
+
+// writeError responds with an error document
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]any{"error": map[string]string{"code": code, "message": message}})
+}
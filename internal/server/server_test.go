@@ -0,0 +1,230 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// newTestServer returns an API server on a new file store holding one task
+func newTestServer(t *testing.T, opts Options) (*httptest.Server, task.Store) {
+	t.Helper()
+	store, err := task.NewFileStoreAt(filepath.Join(t.TempDir(), "tasks.json"))
+	if err != nil {
+		t.Fatalf("NewFileStoreAt: %v", err)
+	}
+	if _, err := store.Add(&task.Task{Description: "Write tests", Status: task.StatusTodo, Priority: task.PriorityMedium}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	srv := httptest.NewServer(New(store, opts))
+	t.Cleanup(srv.Close)
+	return srv, store
+}
+
+// do sends a request and returns the response, whose body is closed when the
+// test ends
+func do(t *testing.T, srv *httptest.Server, method, path, body string, header map[string]string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	res, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	t.Cleanup(func() { res.Body.Close() })
+	return res
+}
+
+// checkError checks the status and the code of an error response
+func checkError(t *testing.T, res *http.Response, status int, code string) {
+	t.Helper()
+	if res.StatusCode != status {
+		t.Fatalf("status = %d, want %d", res.StatusCode, status)
+	}
+	var doc struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		t.Fatalf("error body does not parse: %v", err)
+	}
+	if doc.Error.Code != code || doc.Error.Message == "" {
+		t.Errorf("error = %+v, want code %s with a message", doc.Error, code)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	srv, _ := newTestServer(t, Options{})
+
+	for _, tt := range []struct{ method, path, body string }{
+		{"GET", "/tasks/99", ""},
+		{"PATCH", "/tasks/99", `{"priority": "high"}`},
+		{"DELETE", "/tasks/99", ""},
+		{"POST", "/tasks/99/complete", ""},
+		{"DELETE", "/tasks/1/tags/missing", ""},
+	} {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			checkError(t, do(t, srv, tt.method, tt.path, tt.body, nil), http.StatusNotFound, "not_found")
+		})
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	srv, _ := newTestServer(t, Options{})
+
+	res := do(t, srv, "GET", "/tasks/1", "", nil)
+	tag := res.Header.Get("ETag")
+	if res.StatusCode != http.StatusOK || tag == "" {
+		t.Fatalf("GET = %d with ETag %q", res.StatusCode, tag)
+	}
+
+	res = do(t, srv, "PATCH", "/tasks/1", `{"priority": "high"}`, map[string]string{"If-Match": tag})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("PATCH with the current ETag = %d, want 200", res.StatusCode)
+	}
+	if res.Header.Get("ETag") == tag {
+		t.Error("ETag did not change with the task")
+	}
+
+	// The first PATCH changed the task, so the ETag is stale now
+	res = do(t, srv, "PATCH", "/tasks/1", `{"priority": "low"}`, map[string]string{"If-Match": tag})
+	checkError(t, res, http.StatusPreconditionFailed, "precondition_failed")
+	res = do(t, srv, "DELETE", "/tasks/1", "", map[string]string{"If-Match": tag})
+	checkError(t, res, http.StatusPreconditionFailed, "precondition_failed")
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	srv, _ := newTestServer(t, Options{})
+
+	tag := do(t, srv, "GET", "/tasks/1", "", nil).Header.Get("ETag")
+	res := do(t, srv, "GET", "/tasks/1", "", map[string]string{"If-None-Match": tag})
+	if res.StatusCode != http.StatusNotModified {
+		t.Fatalf("GET with the current ETag = %d, want 304", res.StatusCode)
+	}
+	res = do(t, srv, "GET", "/tasks/1", "", map[string]string{"If-None-Match": `"stale"`})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("GET with another ETag = %d, want 200", res.StatusCode)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	srv, _ := newTestServer(t, Options{Token: "s3cret"})
+
+	for name, header := range map[string]map[string]string{
+		"missing": nil,
+		"wrong":   {"Authorization": "Bearer guess"},
+		"scheme":  {"Authorization": "Basic s3cret"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res := do(t, srv, "GET", "/tasks", "", header)
+			checkError(t, res, http.StatusUnauthorized, "unauthorized")
+			if res.Header.Get("WWW-Authenticate") == "" {
+				t.Error("no WWW-Authenticate header")
+			}
+		})
+	}
+
+	res := do(t, srv, "GET", "/tasks", "", map[string]string{"Authorization": "Bearer s3cret"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("GET with the token = %d, want 200", res.StatusCode)
+	}
+}
+
+func TestBadRequest(t *testing.T) {
+	srv, _ := newTestServer(t, Options{})
+
+	for _, tt := range []struct{ name, method, path, body string }{
+		{"unknown field", "POST", "/tasks", `{"description": "x", "colour": "red"}`},
+		{"unknown patch field", "PATCH", "/tasks/1", `{"prio": "high"}`},
+		{"bad limit", "GET", "/tasks?limit=ten", ""},
+		{"negative offset", "GET", "/tasks?offset=-1", ""},
+		{"bad filter", "GET", "/tasks?filter=((", ""},
+		{"bad id", "GET", "/tasks/abc", ""},
+		{"bad due", "PATCH", "/tasks/1", `{"due": "someday"}`},
+		{"missing parent", "PATCH", "/tasks/1", `{"parent_id": 42}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			checkError(t, do(t, srv, tt.method, tt.path, tt.body, nil), http.StatusBadRequest, "bad_request")
+		})
+	}
+}
+
+func TestPatchCompleteRecurring(t *testing.T) {
+	srv, store := newTestServer(t, Options{})
+
+	res := do(t, srv, "POST", "/tasks", `{"description": "Water plants", "due": "2099-01-05", "recur": "weekly"}`, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("POST = %d, want 201", res.StatusCode)
+	}
+	var created task.Task
+	if err := json.NewDecoder(res.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+
+	res = do(t, srv, "PATCH", "/tasks/"+strconv.Itoa(created.ID), `{"status": "completed"}`, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("PATCH = %d, want 200", res.StatusCode)
+	}
+	var done task.Task
+	if err := json.NewDecoder(res.Body).Decode(&done); err != nil {
+		t.Fatal(err)
+	}
+	if done.Status != task.StatusCompleted || done.CompletedAt == nil {
+		t.Errorf("patched task is %s, completed at %v", done.Status, done.CompletedAt)
+	}
+
+	tasks, err := store.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var next *task.Task
+	for _, other := range tasks {
+		if other.RecurParent == created.ID {
+			next = other
+		}
+	}
+	if next == nil {
+		t.Fatal("completing the task did not add its next occurrence")
+	}
+	if next.Status != task.StatusTodo || next.Due == nil || next.Due.Format("2006-01-02") != "2099-01-12" {
+		t.Errorf("next occurrence is %s, due %v", next.Status, next.Due)
+	}
+}
+
+func TestListTasks(t *testing.T) {
+	srv, store := newTestServer(t, Options{})
+	for _, desc := range []string{"two", "three"} {
+		if _, err := store.Add(&task.Task{Description: desc, Status: task.StatusTodo, Priority: task.PriorityHigh}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res := do(t, srv, "GET", "/tasks?filter=priority:high&sort=id%2B&limit=1&offset=1", "", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("GET = %d, want 200", res.StatusCode)
+	}
+	var page struct {
+		Tasks []task.Task `json:"tasks"`
+		Total int         `json:"total"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 2 || len(page.Tasks) != 1 || page.Tasks[0].Description != "three" {
+		t.Errorf("page = %+v, want task three of 2", page)
+	}
+}
@@ -0,0 +1,103 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vkhangstack/taskman/internal/dates"
+)
+
+// Changes are the fields of a task to set, as sent by API clients. Fields that
+// are nil are left as they are; due and recur are cleared with null or "".
+type Changes struct {
+	Description *string         `json:"description"`
+	Priority    *string         `json:"priority"`
+	Status      *string         `json:"status"`
+	Project     *string         `json:"project"`
+	Tags        *[]string       `json:"tags"`
+	Due         json.RawMessage `json:"due"`
+	Recur       *string         `json:"recur"`
+	ParentID    *int            `json:"parent_id"`
+	DependsOn   *[]int          `json:"depends_on"`
+}
+
+// Apply sets the changed fields on the task. Due dates are parsed relative to
+// now, and a new status is set as by MarkStatus.
+func (c Changes) Apply(t *Task, now time.Time) error {
+	if c.Description != nil {
+		t.Description = strings.TrimSpace(*c.Description)
+	}
+	if c.Priority != nil {
+		t.Priority = *c.Priority
+	}
+	if c.Project != nil {
+		t.Project = strings.TrimSpace(*c.Project)
+	}
+	if c.Tags != nil {
+		t.Tags = []string{}
+		for _, tag := range *c.Tags {
+			if tag = strings.TrimSpace(tag); !t.HasTag(tag) {
+				t.Tags = append(t.Tags, tag)
+			}
+		}
+	}
+	if c.Recur != nil {
+		t.Recur = strings.TrimSpace(*c.Recur)
+	}
+	if c.ParentID != nil {
+		t.ParentID = *c.ParentID
+	}
+	if c.DependsOn != nil {
+		t.DependsOn = *c.DependsOn
+	}
+	if len(c.Due) > 0 {
+		var due *string
+		if err := json.Unmarshal(c.Due, &due); err != nil {
+			return fmt.Errorf("due must be a date string or null")
+		}
+		t.Due = nil
+		if due != nil && *due != "" {
+			d, err := dates.Parse(*due, now)
+			if err != nil {
+				return err
+			}
+			t.Due = &d
+		}
+	}
+	if c.Status != nil && *c.Status != t.Status {
+		t.MarkStatus(*c.Status)
+	}
+	return nil
+}
+
+// CheckRelationsOf returns an error if the parent or the dependencies of the
+// task do not exist or form a cycle with the other tasks. A task without an
+// ID is new and cannot be part of a cycle yet.
+func CheckRelationsOf(t *Task, all []*Task) error {
+	if t.ParentID == 0 && len(t.DependsOn) == 0 {
+		return nil
+	}
+
+	if t.ID == 0 {
+		index := IndexTasks(all)
+		if _, ok := index[t.ParentID]; t.ParentID != 0 && !ok {
+			return fmt.Errorf("parent task %d does not exist", t.ParentID)
+		}
+		for _, dep := range t.DependsOn {
+			if _, ok := index[dep]; !ok {
+				return fmt.Errorf("dependency %d does not exist", dep)
+			}
+		}
+		return nil
+	}
+
+	tasks := []*Task{t}
+	for _, other := range all {
+		if other.ID != t.ID {
+			tasks = append(tasks, other)
+		}
+	}
+	return checkRelations(tasks)
+}
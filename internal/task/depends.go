@@ -0,0 +1,156 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DependsOnTask returns true if the task directly depends on the task with the given ID
+func (t *Task) DependsOnTask(id int) bool {
+	for _, dep := range t.DependsOn {
+		if dep == id {
+			return true
+		}
+	}
+	return false
+}
+
+// AddDependency makes the task depend on the task with the given ID
+func (t *Task) AddDependency(id int) {
+	if !t.DependsOnTask(id) {
+		t.DependsOn = append(t.DependsOn, id)
+		sort.Ints(t.DependsOn)
+	}
+}
+
+// RemoveDependency removes the dependency on the task with the given ID
+func (t *Task) RemoveDependency(id int) {
+	for i, dep := range t.DependsOn {
+		if dep == id {
+			t.DependsOn = append(t.DependsOn[:i], t.DependsOn[i+1:]...)
+			return
+		}
+	}
+}
+
+// Blockers returns the IDs of the dependencies that are not completed yet.
+// Dependencies missing from tasks are ignored.
+func (t *Task) Blockers(tasks map[int]*Task) []int {
+	var blockers []int
+	for _, dep := range t.DependsOn {
+		if d, ok := tasks[dep]; ok && !d.IsCompleted() {
+			blockers = append(blockers, dep)
+		}
+	}
+	return blockers
+}
+
+// IsBlocked returns true if the task is open and waits for an unfinished dependency
+func (t *Task) IsBlocked(tasks map[int]*Task) bool {
+	return t.IsOpen() && len(t.Blockers(tasks)) > 0
+}
+
+// IsBlocking returns true if an open task waits for this one to be completed
+func (t *Task) IsBlocking(tasks map[int]*Task) bool {
+	if t.IsCompleted() {
+		return false
+	}
+	for _, other := range tasks {
+		if other.IsOpen() && other.DependsOnTask(t.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// IndexTasks returns the tasks keyed by ID
+func IndexTasks(tasks []*Task) map[int]*Task {
+	byID := make(map[int]*Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+	return byID
+}
+
+// CheckDependencies returns an error if a task depends on itself or on a task
+// that does not exist, or if the dependencies form a cycle
+func CheckDependencies(tasks []*Task) error {
+	byID := IndexTasks(tasks)
+
+	ids := make([]int, 0, len(tasks))
+	for _, t := range tasks {
+		for _, dep := range t.DependsOn {
+			if dep == t.ID {
+				return fmt.Errorf("task %d cannot depend on itself", t.ID)
+			}
+			if _, ok := byID[dep]; !ok {
+				return fmt.Errorf("task %d depends on task %d, which does not exist", t.ID, dep)
+			}
+		}
+		ids = append(ids, t.ID)
+	}
+	sort.Ints(ids)
+
+	// Depth-first search; reaching a task that is still on the path is a cycle
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := make(map[int]int, len(tasks))
+	var path []int
+
+	var visit func(id int) error
+	visit = func(id int) error {
+		switch state[id] {
+		case onPath:
+			start := 0
+			for path[start] != id {
+				start++
+			}
+			return fmt.Errorf("dependency cycle: %s", formatCycle(append(path[start:], id)))
+		case done:
+			return nil
+		}
+
+		state[id] = onPath
+		path = append(path, id)
+		for _, dep := range byID[id].DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		return nil
+	}
+
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropDependency removes the dependency on id from every task and returns the
+// tasks that changed
+func dropDependency(tasks []*Task, id int) []*Task {
+	var changed []*Task
+	for _, t := range tasks {
+		if t.DependsOnTask(id) {
+			t.RemoveDependency(id)
+			changed = append(changed, t)
+		}
+	}
+	return changed
+}
+
+func formatCycle(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprintf("#%d", id)
+	}
+	return strings.Join(parts, " → ")
+}
@@ -0,0 +1,118 @@
+package task
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheckDependencies(t *testing.T) {
+	tests := []struct {
+		name string
+		deps map[int][]int // dependencies of tasks 1 to 4
+		want string        // empty when the dependencies are valid
+	}{
+		{"none", map[int][]int{}, ""},
+		{"chain", map[int][]int{1: {2}, 2: {3}, 3: {4}}, ""},
+		{"shared dependency", map[int][]int{1: {3}, 2: {3}, 3: {4}}, ""},
+		{"self", map[int][]int{2: {2}}, "task 2 cannot depend on itself"},
+		{"direct cycle", map[int][]int{1: {2}, 2: {1}}, "dependency cycle: #1 → #2 → #1"},
+		{"indirect cycle", map[int][]int{1: {2}, 2: {3}, 3: {1}}, "dependency cycle: #1 → #2 → #3 → #1"},
+		{"cycle off a chain", map[int][]int{1: {2}, 2: {3}, 3: {4}, 4: {2}}, "dependency cycle: #2 → #3 → #4 → #2"},
+		{"missing", map[int][]int{3: {9}}, "task 3 depends on task 9, which does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tasks []*Task
+			for id := 1; id <= 4; id++ {
+				tasks = append(tasks, &Task{ID: id, Status: StatusTodo, DependsOn: tt.deps[id]})
+			}
+
+			err := CheckDependencies(tasks)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("CheckDependencies: %v", err)
+			case tt.want != "" && (err == nil || err.Error() != tt.want):
+				t.Errorf("CheckDependencies error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreRejectsDependencyCycles(t *testing.T) {
+	for _, driver := range testDrivers {
+		t.Run(driver.name, func(t *testing.T) {
+			store := openTestStore(t, driver.open, filepath.Join(t.TempDir(), driver.file))
+			// 1 depends on 2, which depends on 3
+			for i := 0; i < 3; i++ {
+				if _, err := store.Add(&Task{Description: "task", Status: StatusTodo, Priority: PriorityMedium}); err != nil {
+					t.Fatalf("Add: %v", err)
+				}
+			}
+			for id, dep := range map[int]int{1: 2, 2: 3} {
+				tk, err := store.GetByID(id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				tk.AddDependency(dep)
+				if err := store.Update(tk); err != nil {
+					t.Fatalf("Update(%d): %v", id, err)
+				}
+			}
+
+			tests := []struct {
+				name string
+				id   int
+				deps []int
+				want string
+			}{
+				{"self", 3, []int{3}, "cannot depend on itself"},
+				{"indirect cycle", 3, []int{1}, "dependency cycle"},
+				{"missing", 3, []int{9}, "does not exist"},
+			}
+			for _, tt := range tests {
+				tk, err := store.GetByID(tt.id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				tk.DependsOn = tt.deps
+				if err := store.Update(tk); err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("%s: Update error = %v, want one containing %q", tt.name, err, tt.want)
+				}
+			}
+
+			if _, err := store.Add(&Task{Description: "new", Status: StatusTodo, Priority: PriorityLow, DependsOn: []int{9}}); err == nil {
+				t.Error("Add accepted a dependency on a missing task")
+			}
+			if tk, err := store.GetByID(3); err != nil || len(tk.DependsOn) != 0 {
+				t.Errorf("task 3 was saved with dependencies %v (%v)", tk.DependsOn, err)
+			}
+		})
+	}
+}
+
+func TestBlockers(t *testing.T) {
+	tasks := []*Task{
+		{ID: 1, Status: StatusTodo, DependsOn: []int{2, 3, 4}},
+		{ID: 2, Status: StatusCompleted},
+		{ID: 3, Status: StatusInProgress},
+		{ID: 4, Status: StatusTodo},
+		{ID: 5, Status: StatusCompleted, DependsOn: []int{4}},
+	}
+	byID := IndexTasks(tasks)
+
+	if got := tasks[0].Blockers(byID); !slices.Equal(got, []int{3, 4}) {
+		t.Errorf("Blockers = %v, want [3 4]", got)
+	}
+	if !tasks[0].IsBlocked(byID) {
+		t.Error("task 1 is not blocked")
+	}
+	// Completed tasks are neither blocked nor blocking
+	if tasks[4].IsBlocked(byID) || tasks[1].IsBlocking(byID) {
+		t.Error("a completed task counts as blocked or blocking")
+	}
+	if !tasks[3].IsBlocking(byID) {
+		t.Error("task 4 does not block task 1")
+	}
+}
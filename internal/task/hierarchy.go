@@ -0,0 +1,142 @@
+package task
+
+import "fmt"
+
+// TreeEntry is a task and its depth in the task hierarchy
+type TreeEntry struct {
+	Task  *Task
+	Depth int
+}
+
+// Progress counts the completed subtasks of a task
+type Progress struct {
+	Done  int
+	Total int
+}
+
+// Percent returns the completed share of the subtasks, rounded down
+func (p Progress) Percent() int {
+	if p.Total == 0 {
+		return 0
+	}
+	return p.Done * 100 / p.Total
+}
+
+// ChildrenIndex groups tasks by the ID of their parent, keeping their order
+func ChildrenIndex(tasks []*Task) map[int][]*Task {
+	children := make(map[int][]*Task)
+	for _, t := range tasks {
+		if t.ParentID != 0 {
+			children[t.ParentID] = append(children[t.ParentID], t)
+		}
+	}
+	return children
+}
+
+// Descendants returns every subtask below the task with the given ID, parents
+// before their children
+func Descendants(children map[int][]*Task, id int) []*Task {
+	var result []*Task
+	seen := map[int]bool{id: true}
+	var walk func(id int)
+	walk = func(id int) {
+		for _, c := range children[id] {
+			if seen[c.ID] {
+				continue
+			}
+			seen[c.ID] = true
+			result = append(result, c)
+			walk(c.ID)
+		}
+	}
+	walk(id)
+	return result
+}
+
+// RollUp counts how many of the task's subtasks, at any depth, are completed
+func RollUp(children map[int][]*Task, id int) Progress {
+	var p Progress
+	for _, d := range Descendants(children, id) {
+		p.Total++
+		if d.IsCompleted() {
+			p.Done++
+		}
+	}
+	return p
+}
+
+// FlattenTree orders tasks depth first, each task followed by its subtasks.
+// Tasks whose parent is not among tasks are treated as roots. The relative
+// order of siblings is kept.
+func FlattenTree(tasks []*Task) []TreeEntry {
+	present := IndexTasks(tasks)
+	children := ChildrenIndex(tasks)
+
+	var entries []TreeEntry
+	seen := make(map[int]bool)
+	var walk func(t *Task, depth int)
+	walk = func(t *Task, depth int) {
+		if seen[t.ID] {
+			return
+		}
+		seen[t.ID] = true
+		entries = append(entries, TreeEntry{Task: t, Depth: depth})
+		for _, c := range children[t.ID] {
+			walk(c, depth+1)
+		}
+	}
+
+	for _, t := range tasks {
+		if _, ok := present[t.ParentID]; !ok || t.ParentID == 0 {
+			walk(t, 0)
+		}
+	}
+	return entries
+}
+
+// CheckHierarchy returns an error if a task's parent does not exist or if a
+// task is its own ancestor
+func CheckHierarchy(tasks []*Task) error {
+	byID := IndexTasks(tasks)
+	for _, t := range tasks {
+		if t.ParentID == 0 {
+			continue
+		}
+		if _, ok := byID[t.ParentID]; !ok {
+			return fmt.Errorf("parent task %d of task %d does not exist", t.ParentID, t.ID)
+		}
+
+		path := []int{t.ID}
+		for p := t.ParentID; p != 0; p = byID[p].ParentID {
+			path = append(path, p)
+			if p == t.ID {
+				return fmt.Errorf("task %d cannot be its own ancestor: %s", t.ID, formatCycle(path))
+			}
+			if _, ok := byID[p]; !ok || len(path) > len(tasks)+1 {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// checkRelations validates the dependencies and the hierarchy of the tasks
+func checkRelations(tasks []*Task) error {
+	if err := CheckDependencies(tasks); err != nil {
+		return err
+	}
+	return CheckHierarchy(tasks)
+}
+
+// promoteChildren moves the subtasks of the removed task up to its parent
+// and returns the tasks that changed
+func promoteChildren(tasks []*Task, removed *Task) []*Task {
+	var changed []*Task
+	for _, t := range tasks {
+		if t.ParentID == removed.ID {
+			t.ParentID = removed.ParentID
+			changed = append(changed, t)
+		}
+	}
+	return changed
+}
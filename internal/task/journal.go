@@ -0,0 +1,340 @@
+package task
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Operations recorded in the journal
+const (
+	OpAdd      = "add"
+	OpUpdate   = "update"
+	OpDelete   = "delete"
+	OpComplete = "complete"
+	OpStatus   = "status"
+	OpTag      = "tag"
+	OpProject  = "project"
+	OpTimer    = "timer"
+	OpImport   = "import"
+	OpUndo     = "undo"
+	OpRedo     = "redo"
+)
+
+// HistoryStore is implemented by stores that journal their mutations and can
+// revert them
+type HistoryStore interface {
+	// Undo reverts the last n operations that are still in effect
+	Undo(n int, force bool) ([]*JournalEntry, error)
+	// UndoTask reverts the last operation in effect that touched the task
+	UndoTask(id int, force bool) (*JournalEntry, error)
+	// Redo reapplies the last n undone operations
+	Redo(n int, force bool) ([]*JournalEntry, error)
+	// History returns the journal entries touching the task, or all entries for id 0
+	History(id int) ([]*JournalEntry, error)
+}
+
+// Change is the state of one task before and after an operation. A nil
+// Before means the task was created, a nil After that it was deleted.
+type Change struct {
+	TaskID int   `json:"task_id"`
+	Before *Task `json:"before,omitempty"`
+	After  *Task `json:"after,omitempty"`
+}
+
+// JournalEntry records one store operation and the task changes it made
+type JournalEntry struct {
+	Seq     int       `json:"seq"`
+	Time    time.Time `json:"time"`
+	Op      string    `json:"op"`
+	Ref     int       `json:"ref,omitempty"` // entry reverted by an undo or reapplied by a redo
+	Changes []Change  `json:"changes"`
+
+	// Undone is set by History for operations that are currently undone
+	Undone bool `json:"-"`
+}
+
+// Touches returns true if the entry changed the given task
+func (e *JournalEntry) Touches(id int) bool {
+	for _, c := range e.Changes {
+		if c.TaskID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary describes what a change did, e.g. `status: todo → completed`
+func (c Change) Summary() string {
+	switch {
+	case c.Before == nil && c.After == nil:
+		return ""
+	case c.Before == nil:
+		return fmt.Sprintf("created %q", c.After.Description)
+	case c.After == nil:
+		return fmt.Sprintf("deleted %q", c.Before.Description)
+	}
+
+	b, a := c.Before, c.After
+	var parts []string
+	if b.Description != a.Description {
+		parts = append(parts, fmt.Sprintf("description: %q → %q", b.Description, a.Description))
+	}
+	if b.Status != a.Status {
+		parts = append(parts, fmt.Sprintf("status: %s → %s", b.Status, a.Status))
+	}
+	if b.Priority != a.Priority {
+		parts = append(parts, fmt.Sprintf("priority: %s → %s", b.Priority, a.Priority))
+	}
+	if strings.Join(b.Tags, ",") != strings.Join(a.Tags, ",") {
+		parts = append(parts, fmt.Sprintf("tags: [%s] → [%s]", strings.Join(b.Tags, ","), strings.Join(a.Tags, ",")))
+	}
+	if formatOptionalTime(b.Due) != formatOptionalTime(a.Due) {
+		parts = append(parts, fmt.Sprintf("due: %s → %s", formatOptionalTime(b.Due), formatOptionalTime(a.Due)))
+	}
+	if fmt.Sprint(b.DependsOn) != fmt.Sprint(a.DependsOn) {
+		parts = append(parts, fmt.Sprintf("depends: %v → %v", b.DependsOn, a.DependsOn))
+	}
+	if b.Project != a.Project {
+		parts = append(parts, fmt.Sprintf("project: %q → %q", b.Project, a.Project))
+	}
+	if b.ParentID != a.ParentID {
+		parts = append(parts, fmt.Sprintf("parent: %d → %d", b.ParentID, a.ParentID))
+	}
+	if b.Recur != a.Recur {
+		parts = append(parts, fmt.Sprintf("recur: %q → %q", b.Recur, a.Recur))
+	}
+	if len(b.Annotations) != len(a.Annotations) {
+		parts = append(parts, fmt.Sprintf("annotations: %d → %d", len(b.Annotations), len(a.Annotations)))
+	}
+	if len(parts) == 0 {
+		return "updated"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "none"
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// Journal is an append-only log of store operations, one JSON entry per line
+type Journal struct {
+	path string
+}
+
+// NewJournal returns a journal stored at path
+func NewJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// Append writes entries to the end of the journal and flushes them to disk
+func (j *Journal) Append(entries ...*JournalEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to marshal journal entry: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return f.Sync()
+}
+
+// Entries reads every entry of the journal in order
+func (j *Journal) Entries() ([]*JournalEntry, error) {
+	f, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	var entries []*JournalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var e JournalEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			// A crash while appending can leave a partial last line behind
+			continue
+		}
+		entries = append(entries, &e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	return entries, nil
+}
+
+// journalState replays undo and redo markers and returns the operations still
+// in effect and the undone operations that can be redone, both oldest first
+func journalState(entries []*JournalEntry) (applied, undone []*JournalEntry) {
+	bySeq := make(map[int]*JournalEntry)
+	remove := func(list []*JournalEntry, seq int) []*JournalEntry {
+		for i, e := range list {
+			if e.Seq == seq {
+				return append(list[:i:i], list[i+1:]...)
+			}
+		}
+		return list
+	}
+
+	for _, e := range entries {
+		bySeq[e.Seq] = e
+		switch e.Op {
+		case OpUndo:
+			if ref, ok := bySeq[e.Ref]; ok {
+				applied = remove(applied, ref.Seq)
+				undone = append(undone, ref)
+			}
+		case OpRedo:
+			if ref, ok := bySeq[e.Ref]; ok {
+				undone = remove(undone, ref.Seq)
+				applied = append(applied, ref)
+			}
+		default:
+			applied = append(applied, e)
+			// A new operation makes the undone ones unreachable for redo
+			undone = nil
+		}
+	}
+
+	return applied, undone
+}
+
+// snapshotTasks returns the JSON encoding of every task keyed by ID
+func snapshotTasks(tasks []*Task) (map[int][]byte, error) {
+	snap := make(map[int][]byte, len(tasks))
+	for _, t := range tasks {
+		b, err := json.Marshal(t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal task: %w", err)
+		}
+		snap[t.ID] = b
+	}
+	return snap, nil
+}
+
+// diffTasks compares two snapshots and returns the changes between them
+// ordered by task ID
+func diffTasks(before, after map[int][]byte) ([]Change, error) {
+	var changes []Change
+	for id, b := range before {
+		if a, ok := after[id]; !ok || !bytes.Equal(a, b) {
+			c := Change{TaskID: id}
+			if err := decodeSnapshot(b, &c.Before); err != nil {
+				return nil, err
+			}
+			if ok {
+				if err := decodeSnapshot(a, &c.After); err != nil {
+					return nil, err
+				}
+			}
+			changes = append(changes, c)
+		}
+	}
+	for id, a := range after {
+		if _, ok := before[id]; !ok {
+			c := Change{TaskID: id}
+			if err := decodeSnapshot(a, &c.After); err != nil {
+				return nil, err
+			}
+			changes = append(changes, c)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].TaskID < changes[j].TaskID })
+	return changes, nil
+}
+
+func decodeSnapshot(b []byte, t **Task) error {
+	*t = new(Task)
+	if err := json.Unmarshal(b, *t); err != nil {
+		return fmt.Errorf("failed to parse task: %w", err)
+	}
+	return nil
+}
+
+// applyChanges moves every task touched by changes back to its Before state
+// (revert) or forward to its After state. Unless force is set it refuses to
+// overwrite tasks that were modified after the operation.
+func applyChanges(data *TaskData, seq int, changes []Change, revert, force bool) error {
+	current, err := snapshotTasks(data.Tasks)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		expected, target := c.After, c.Before
+		if !revert {
+			expected, target = c.Before, c.After
+		}
+
+		if !force {
+			var want []byte
+			if expected != nil {
+				if want, err = json.Marshal(expected); err != nil {
+					return fmt.Errorf("failed to marshal task: %w", err)
+				}
+			}
+			if !bytes.Equal(current[c.TaskID], want) {
+				return fmt.Errorf("task %d was modified after operation %d; use --force to overwrite it", c.TaskID, seq)
+			}
+		}
+
+		setTask(data, c.TaskID, target)
+	}
+
+	return nil
+}
+
+// setTask replaces, inserts or (for a nil task) removes the task with the given ID
+func setTask(data *TaskData, id int, t *Task) {
+	for i, existing := range data.Tasks {
+		if existing.ID == id {
+			if t == nil {
+				data.Tasks = append(data.Tasks[:i], data.Tasks[i+1:]...)
+			} else {
+				data.Tasks[i] = t
+			}
+			return
+		}
+	}
+
+	if t != nil {
+		data.Tasks = append(data.Tasks, t)
+		if id >= data.NextID {
+			data.NextID = id + 1
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package task
+
+import "os"
+
+// lockFile is a no-op on platforms without advisory file locking
+func lockFile(f *os.File, exclusive bool) error {
+	return nil
+}
+
+// unlockFile is a no-op on platforms without advisory file locking
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,64 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidateProject checks a dotted project name such as work.backend.api
+func ValidateProject(project string) error {
+	if project == "" {
+		return nil
+	}
+	for _, part := range strings.Split(project, ".") {
+		if part == "" {
+			return fmt.Errorf("invalid project %q: empty segment", project)
+		}
+		if strings.ContainsAny(part, " \t\n,:+") {
+			return fmt.Errorf("invalid project %q: segments cannot contain spaces or any of , : +", project)
+		}
+	}
+	return nil
+}
+
+// InProject returns true if the task belongs to the project or to one of its
+// subprojects, so that a task in work.backend is in work
+func (t *Task) InProject(project string) bool {
+	return IsSubproject(t.Project, project)
+}
+
+// IsSubproject returns true if name is project itself or lies below it
+func IsSubproject(name, project string) bool {
+	return name == project || strings.HasPrefix(name, project+".")
+}
+
+// MoveProject returns name with the project prefix from replaced by to, and
+// false if name is not in from
+func MoveProject(name, from, to string) (string, bool) {
+	if !IsSubproject(name, from) {
+		return name, false
+	}
+	return to + strings.TrimPrefix(name, from), true
+}
+
+// Projects returns every project used by the tasks together with all their
+// parent projects, sorted by name
+func Projects(tasks []*Task) []string {
+	seen := make(map[string]bool)
+	for _, t := range tasks {
+		parts := strings.Split(t.Project, ".")
+		for i := range parts {
+			if t.Project != "" {
+				seen[strings.Join(parts[:i+1], ".")] = true
+			}
+		}
+	}
+
+	projects := make([]string, 0, len(seen))
+	for p := range seen {
+		projects = append(projects, p)
+	}
+	sort.Strings(projects)
+	return projects
+}
@@ -0,0 +1,69 @@
+package task
+
+import "sort"
+
+// Series groups the instances of a recurring task
+type Series struct {
+	ID    int     // ID of the first task of the series
+	Rule  string  // recurrence rule of the latest instance
+	Tasks []*Task // instances, oldest first
+}
+
+// Current returns the open instance of the series, or nil if there is none
+func (s *Series) Current() *Task {
+	for i := len(s.Tasks) - 1; i >= 0; i-- {
+		if s.Tasks[i].IsOpen() {
+			return s.Tasks[i]
+		}
+	}
+	return nil
+}
+
+// Completed returns the number of completed instances
+func (s *Series) Completed() int {
+	count := 0
+	for _, t := range s.Tasks {
+		if t.IsCompleted() {
+			count++
+		}
+	}
+	return count
+}
+
+// IsActive returns true if the series will keep spawning occurrences
+func (s *Series) IsActive() bool {
+	current := s.Current()
+	return current != nil && current.IsRecurring()
+}
+
+// GroupSeries collects recurring tasks, and tasks spawned by them, into series
+// ordered by series ID
+func GroupSeries(tasks []*Task) []*Series {
+	byID := make(map[int]*Series)
+	for _, t := range tasks {
+		if !t.IsRecurring() && t.RecurParent == 0 {
+			continue
+		}
+
+		s, ok := byID[t.SeriesID()]
+		if !ok {
+			s = &Series{ID: t.SeriesID()}
+			byID[s.ID] = s
+		}
+		s.Tasks = append(s.Tasks, t)
+	}
+
+	var series []*Series
+	for _, s := range byID {
+		sort.Slice(s.Tasks, func(i, j int) bool { return s.Tasks[i].ID < s.Tasks[j].ID })
+		for _, t := range s.Tasks {
+			if t.IsRecurring() {
+				s.Rule = t.Recur
+			}
+		}
+		series = append(series, s)
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].ID < series[j].ID })
+
+	return series
+}
@@ -0,0 +1,166 @@
+package task
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SortKey is a field to sort tasks by
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// sortFields maps the sort field names and their aliases to the field, and
+// tells whether the field sorts descending when no direction is given
+var sortFields = map[string]struct {
+	field      string
+	descending bool
+}{
+	"id":          {"id", false},
+	"status":      {"status", false},
+	"priority":    {"priority", true},
+	"urgency":     {"urgency", true},
+	"project":     {"project", false},
+	"description": {"description", false},
+	"desc":        {"description", false},
+	"due":         {"due", false},
+	"created":     {"created", false},
+	"updated":     {"updated", false},
+	"completed":   {"completed", false},
+}
+
+// ParseSort parses a comma-separated list of sort keys such as
+// "priority-,created+". A trailing + sorts ascending and - descending; without
+// one, priority and urgency put the highest first and other fields sort
+// ascending.
+func ParseSort(spec string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+
+		name, dir := part, ""
+		if last := part[len(part)-1]; last == '+' || last == '-' {
+			name, dir = part[:len(part)-1], string(last)
+		}
+		f, ok := sortFields[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid sort key: %s. Valid keys are: id, status, priority, urgency, project, description, due, created, updated, completed", name)
+		}
+
+		key := SortKey{Field: f.field, Descending: f.descending}
+		switch dir {
+		case "+":
+			key.Descending = false
+		case "-":
+			key.Descending = true
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// SortTasks orders the tasks by the keys, with later keys breaking ties of
+// earlier ones. Tasks without a value for a field, such as a due date, come
+// last in either direction. urgency holds the scores used by the urgency key.
+func SortTasks(tasks []*Task, keys []SortKey, urgency map[int]float64) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		for _, k := range keys {
+			c, missing := compareField(tasks[i], tasks[j], k.Field, urgency)
+			if c == 0 {
+				continue
+			}
+			if k.Descending && !missing {
+				c = -c
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+// compareField compares a field of two tasks. missing is true when only one of
+// them has a value, in which case the task with the value comes first.
+func compareField(a, b *Task, field string, urgency map[int]float64) (c int, missing bool) {
+	switch field {
+	case "id":
+		return cmp.Compare(a.ID, b.ID), false
+	case "status":
+		return cmp.Compare(statusRank(a.Status), statusRank(b.Status)), false
+	case "priority":
+		return cmp.Compare(priorityRank(a.Priority), priorityRank(b.Priority)), false
+	case "urgency":
+		return cmp.Compare(urgency[a.ID], urgency[b.ID]), false
+	case "project":
+		return compareMissing(a.Project == "", b.Project == "", func() int {
+			return strings.Compare(a.Project, b.Project)
+		})
+	case "description":
+		return strings.Compare(strings.ToLower(a.Description), strings.ToLower(b.Description)), false
+	case "due":
+		return compareTimes(a.Due, b.Due)
+	case "created":
+		return a.CreatedAt.Compare(b.CreatedAt), false
+	case "updated":
+		return a.UpdatedAt.Compare(b.UpdatedAt), false
+	case "completed":
+		return compareTimes(a.CompletedAt, b.CompletedAt)
+	}
+	return 0, false
+}
+
+func compareTimes(a, b *time.Time) (int, bool) {
+	return compareMissing(a == nil, b == nil, func() int { return a.Compare(*b) })
+}
+
+// compareMissing puts a value before a missing one and otherwise compares the
+// values with compare
+func compareMissing(aMissing, bMissing bool, compare func() int) (int, bool) {
+	switch {
+	case aMissing && bMissing:
+		return 0, false
+	case aMissing:
+		return 1, true
+	case bMissing:
+		return -1, true
+	}
+	return compare(), false
+}
+
+// statusRank orders statuses from active to finished
+func statusRank(status string) int {
+	switch status {
+	case StatusInProgress:
+		return 0
+	case StatusTodo:
+		return 1
+	case StatusPending:
+		return 2
+	case StatusCompleted:
+		return 3
+	case StatusArchived:
+		return 4
+	case StatusDeleted:
+		return 5
+	}
+	return 6
+}
+
+// priorityRank orders priorities from low to high
+func priorityRank(priority string) int {
+	switch priority {
+	case PriorityLow:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityHigh:
+		return 3
+	}
+	return 0
+}
@@ -0,0 +1,595 @@
+package task
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore implements the Store interface on top of a SQLite database.
+// Each task is kept as a JSON document, with status, priority and tags
+// mirrored into indexed columns for fast lookups.
+type SQLiteStore struct {
+	db   *sql.DB
+	path string
+}
+
+var _ Store = (*SQLiteStore)(nil)
+
+// sqliteMigrations holds the schema changes applied in order, tracked
+// through PRAGMA user_version
+var sqliteMigrations = []string{
+	`CREATE TABLE tasks (
+		id       INTEGER PRIMARY KEY AUTOINCREMENT,
+		status   TEXT NOT NULL,
+		priority TEXT NOT NULL,
+		data     TEXT NOT NULL
+	);
+	CREATE INDEX idx_tasks_status ON tasks(status);
+	CREATE INDEX idx_tasks_priority ON tasks(priority);
+	CREATE TABLE task_tags (
+		task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		tag     TEXT NOT NULL,
+		PRIMARY KEY (task_id, tag)
+	);
+	CREATE INDEX idx_task_tags_tag ON task_tags(tag);`,
+}
+
+// NewSQLiteStore opens (and creates if needed) a SQLite task database
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() +
+		"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	store := &SQLiteStore{db: db, path: path}
+	if err := store.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	return store, nil
+}
+
+// Path returns the location of the database file
+func (s *SQLiteStore) Path() string {
+	return s.path
+}
+
+// Close closes the underlying database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Add adds a new task and returns its ID
+func (s *SQLiteStore) Add(task *Task) (int, error) {
+	err := s.inTx(func(tx *sql.Tx) error {
+		if err := insertTask(tx, task); err != nil {
+			return err
+		}
+		if len(task.DependsOn) == 0 && task.ParentID == 0 {
+			return nil
+		}
+		return checkRelationsTx(tx)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to add task: %w", err)
+	}
+
+	return task.ID, nil
+}
+
+// GetAll returns all tasks
+func (s *SQLiteStore) GetAll() ([]*Task, error) {
+	rows, err := s.db.Query(`SELECT data FROM tasks ORDER BY id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*Task
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read task: %w", err)
+		}
+		t, err := decodeTask(data)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+
+	return tasks, rows.Err()
+}
+
+// GetByID returns a task by its ID
+func (s *SQLiteStore) GetByID(id int) (*Task, error) {
+	return readTask(s.db.QueryRow(`SELECT data FROM tasks WHERE id = ?`, id), id)
+}
+
+// Update updates an existing task
+func (s *SQLiteStore) Update(updatedTask *Task) error {
+	return s.modify(updatedTask.ID, func(t *Task) error {
+		*t = *updatedTask
+		t.UpdatedAt = time.Now()
+		updatedTask.UpdatedAt = t.UpdatedAt
+		return nil
+	})
+}
+
+// Delete removes a task by its ID
+func (s *SQLiteStore) Delete(id int) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		deleted, err := readTask(tx.QueryRow(`SELECT data FROM tasks WHERE id = ?`, id), id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM tasks WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete task: %w", err)
+		}
+
+		// Tasks that depended on the deleted one are no longer blocked by it,
+		// and its subtasks move up to its parent
+		tasks, err := readAllTasks(tx)
+		if err != nil {
+			return err
+		}
+		changed := append(dropDependency(tasks, id), promoteChildren(tasks, deleted)...)
+		for _, t := range changed {
+			t.UpdatedAt = time.Now()
+			if err := writeTask(tx, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Complete marks a task as completed. Completing a recurring task adds the
+// next occurrence of its series.
+func (s *SQLiteStore) Complete(id int) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		t, err := readTask(tx.QueryRow(`SELECT data FROM tasks WHERE id = ?`, id), id)
+		if err != nil {
+			return err
+		}
+		if t.IsCompleted() {
+			return nil
+		}
+
+		t.MarkCompleted()
+		if err := writeTask(tx, t); err != nil {
+			return err
+		}
+
+		first, err := readTask(tx.QueryRow(`SELECT data FROM tasks WHERE id = ?`, t.SeriesID()), t.SeriesID())
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		next, err := t.NextOccurrence(time.Now(), first)
+		if err != nil || next == nil {
+			return err
+		}
+		return insertTask(tx, next)
+	})
+}
+
+// MarkPending marks a task as pending
+func (s *SQLiteStore) MarkPending(id int) error {
+	return s.MarkStatus(id, StatusPending)
+}
+
+// MarkTodo marks a task as todo
+func (s *SQLiteStore) MarkTodo(id int) error {
+	return s.MarkStatus(id, StatusTodo)
+}
+
+// MarkInProgress marks a task as in progress
+func (s *SQLiteStore) MarkInProgress(id int) error {
+	return s.MarkStatus(id, StatusInProgress)
+}
+
+// MarkDeleted marks a task as deleted without removing it
+func (s *SQLiteStore) MarkDeleted(id int) error {
+	return s.MarkStatus(id, StatusDeleted)
+}
+
+// MarkArchived marks a task as archived
+func (s *SQLiteStore) MarkArchived(id int) error {
+	return s.MarkStatus(id, StatusArchived)
+}
+
+// MarkCompleted marks a task as completed
+func (s *SQLiteStore) MarkCompleted(id int) error {
+	return s.Complete(id)
+}
+
+// MarkStatus sets the status of a task
+func (s *SQLiteStore) MarkStatus(id int, status string) error {
+	if status == StatusCompleted {
+		return s.Complete(id)
+	}
+	return s.modify(id, func(t *Task) error {
+		t.MarkStatus(status)
+		return nil
+	})
+}
+
+// IsCompleted returns true if the task exists and is completed
+func (s *SQLiteStore) IsCompleted(id int) bool {
+	return s.exists(`SELECT 1 FROM tasks WHERE id = ? AND status = ?`, id, StatusCompleted)
+}
+
+// IsPending returns true if the task exists and is pending
+func (s *SQLiteStore) IsPending(id int) bool {
+	return s.exists(`SELECT 1 FROM tasks WHERE id = ? AND status = ?`, id, StatusPending)
+}
+
+// IsHighPriority returns true if the task exists and has high priority
+func (s *SQLiteStore) IsHighPriority(id int) bool {
+	return s.exists(`SELECT 1 FROM tasks WHERE id = ? AND priority = ?`, id, PriorityHigh)
+}
+
+// IsLowPriority returns true if the task exists and has low priority
+func (s *SQLiteStore) IsLowPriority(id int) bool {
+	return s.exists(`SELECT 1 FROM tasks WHERE id = ? AND priority = ?`, id, PriorityLow)
+}
+
+// IsMediumPriority returns true if the task exists and has medium priority
+func (s *SQLiteStore) IsMediumPriority(id int) bool {
+	return s.exists(`SELECT 1 FROM tasks WHERE id = ? AND priority = ?`, id, PriorityMedium)
+}
+
+// HasTag returns true if the task exists and has the specified tag
+func (s *SQLiteStore) HasTag(id int, tag string) bool {
+	return s.exists(`SELECT 1 FROM task_tags WHERE task_id = ? AND tag = ?`, id, tag)
+}
+
+// AddTag adds a tag to a task
+func (s *SQLiteStore) AddTag(id int, tag string) error {
+	return s.modify(id, func(t *Task) error {
+		t.AddTag(tag)
+		return nil
+	})
+}
+
+// RemoveTag removes a tag from a task
+func (s *SQLiteStore) RemoveTag(id int, tag string) error {
+	return s.modify(id, func(t *Task) error {
+		t.RemoveTag(tag)
+		return nil
+	})
+}
+
+// RenameProject moves every task in the project, or in one of its
+// subprojects, to the new project and returns how many tasks were moved
+func (s *SQLiteStore) RenameProject(from, to string) (int, error) {
+	moved := 0
+	err := s.inTx(func(tx *sql.Tx) error {
+		tasks, err := readAllTasks(tx)
+		if err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			if project, ok := MoveProject(t.Project, from, to); ok {
+				t.Project = project
+				t.UpdatedAt = time.Now()
+				if err := writeTask(tx, t); err != nil {
+					return err
+				}
+				moved++
+			}
+		}
+		return nil
+	})
+	return moved, err
+}
+
+// StartTimer starts tracking time on a task, stopping the timer running on
+// any other task, and returns the ID of that task or 0
+func (s *SQLiteStore) StartTimer(id int) (int, error) {
+	stoppedID := 0
+	err := s.inTx(func(tx *sql.Tx) error {
+		tasks, err := readAllTasks(tx)
+		if err != nil {
+			return err
+		}
+		stopped, err := startTimer(tasks, id, time.Now())
+		if err != nil {
+			return err
+		}
+		if stopped != nil {
+			stoppedID = stopped.ID
+			if err := writeTask(tx, stopped); err != nil {
+				return err
+			}
+		}
+		for _, t := range tasks {
+			if t.ID == id {
+				return writeTask(tx, t)
+			}
+		}
+		return nil
+	})
+	return stoppedID, err
+}
+
+// StopTimer stops the timer of a task, or the running one for id 0, and
+// returns the ID of the task it was running on
+func (s *SQLiteStore) StopTimer(id int) (int, error) {
+	stoppedID := 0
+	err := s.inTx(func(tx *sql.Tx) error {
+		tasks, err := readAllTasks(tx)
+		if err != nil {
+			return err
+		}
+		stopped, err := stopTimer(tasks, id, time.Now())
+		if err != nil {
+			return err
+		}
+		stoppedID = stopped.ID
+		return writeTask(tx, stopped)
+	})
+	return stoppedID, err
+}
+
+// Import adds tasks in one transaction, keeping their creation and update
+// times when set, and returns their IDs. Tasks that already have an ID
+// replace the stored task with that ID. Once every task has its ID, link is
+// called, if not nil, to set relations between them.
+func (s *SQLiteStore) Import(tasks []*Task, link func() error) ([]int, error) {
+	var ids []int
+	err := s.inTx(func(tx *sql.Tx) error {
+		for _, t := range tasks {
+			if t.ID != 0 {
+				if _, err := readTask(tx.QueryRow(`SELECT data FROM tasks WHERE id = ?`, t.ID), t.ID); err != nil {
+					return err
+				}
+			} else {
+				created, updated := t.CreatedAt, t.UpdatedAt
+				if err := insertTask(tx, t); err != nil {
+					return err
+				}
+				if !created.IsZero() {
+					t.CreatedAt = created
+				}
+				if !updated.IsZero() {
+					t.UpdatedAt = updated
+				}
+			}
+			ids = append(ids, t.ID)
+		}
+
+		if link != nil {
+			if err := link(); err != nil {
+				return err
+			}
+		}
+		for _, t := range tasks {
+			if err := writeTask(tx, t); err != nil {
+				return err
+			}
+		}
+		return checkRelationsTx(tx)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to import tasks: %w", err)
+	}
+	return ids, nil
+}
+
+// ImportFileStore copies every task from a JSON file store into the database,
+// keeping task IDs. It refuses to run against a database that already holds
+// tasks and returns the number of tasks copied.
+func (s *SQLiteStore) ImportFileStore(src *FileStore) (int, error) {
+	data, err := src.read()
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.inTx(func(tx *sql.Tx) error {
+		var count int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM tasks`).Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("database already contains %d tasks", count)
+		}
+
+		for _, t := range data.Tasks {
+			if _, err := tx.Exec(`INSERT INTO tasks (id, status, priority, data) VALUES (?, ?, ?, '{}')`, t.ID, t.Status, t.Priority); err != nil {
+				return err
+			}
+			if err := writeTask(tx, t); err != nil {
+				return err
+			}
+		}
+
+		// Keep IDs of tasks deleted before the migration from being reused
+		if _, err := tx.Exec(`DELETE FROM sqlite_sequence WHERE name = 'tasks'`); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`INSERT INTO sqlite_sequence (name, seq) VALUES ('tasks', ?)`, data.NextID-1)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to import tasks: %w", err)
+	}
+
+	return len(data.Tasks), nil
+}
+
+// modify loads a task, applies fn and writes it back in a single transaction
+func (s *SQLiteStore) modify(id int, fn func(t *Task) error) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		t, err := readTask(tx.QueryRow(`SELECT data FROM tasks WHERE id = ?`, id), id)
+		if err != nil {
+			return err
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+		t.ID = id
+		if err := writeTask(tx, t); err != nil {
+			return err
+		}
+		// Only a task's own dependencies and parent can introduce a cycle through it
+		if len(t.DependsOn) == 0 && t.ParentID == 0 {
+			return nil
+		}
+		return checkRelationsTx(tx)
+	})
+}
+
+// checkRelationsTx validates the dependencies and parents of every task as seen by tx
+func checkRelationsTx(tx *sql.Tx) error {
+	tasks, err := readAllTasks(tx)
+	if err != nil {
+		return err
+	}
+	return checkRelations(tasks)
+}
+
+// readAllTasks reads every task within tx
+func readAllTasks(tx *sql.Tx) ([]*Task, error) {
+	rows, err := tx.Query(`SELECT data FROM tasks ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*Task
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read task: %w", err)
+		}
+		t, err := decodeTask(data)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// inTx runs fn inside a transaction, committing only if it succeeds
+func (s *SQLiteStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// exists reports whether the query returns at least one row
+func (s *SQLiteStore) exists(query string, args ...any) bool {
+	var one int
+	return s.db.QueryRow(query, args...).Scan(&one) == nil
+}
+
+// migrate brings the schema up to date
+func (s *SQLiteStore) migrate() error {
+	for {
+		done := false
+		err := s.inTx(func(tx *sql.Tx) error {
+			// Read the version inside the write transaction, so that processes
+			// opening a new database at the same time apply each migration once
+			var version int
+			if err := tx.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+				return err
+			}
+			if version >= len(sqliteMigrations) {
+				done = true
+				return nil
+			}
+
+			if _, err := tx.Exec(sqliteMigrations[version]); err != nil {
+				return err
+			}
+			_, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, version+1))
+			return err
+		})
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// insertTask adds a new task row, assigning the task its ID and timestamps
+func insertTask(tx *sql.Tx, t *Task) error {
+	res, err := tx.Exec(`INSERT INTO tasks (status, priority, data) VALUES (?, ?, '{}')`, t.Status, t.Priority)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	t.ID = int(id)
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = time.Now()
+	return writeTask(tx, t)
+}
+
+// writeTask stores the task document and refreshes its indexed columns
+func writeTask(tx *sql.Tx, t *Task) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE tasks SET status = ?, priority = ?, data = ? WHERE id = ?`, t.Status, t.Priority, string(data), t.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM task_tags WHERE task_id = ?`, t.ID); err != nil {
+		return err
+	}
+	for _, tag := range t.Tags {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO task_tags (task_id, tag) VALUES (?, ?)`, t.ID, tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readTask decodes the task document returned by row
+func readTask(row *sql.Row, id int) (*Task, error) {
+	var data string
+	if err := row.Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, notFound(id)
+		}
+		return nil, fmt.Errorf("failed to read task: %w", err)
+	}
+	return decodeTask(data)
+}
+
+// decodeTask unmarshals a stored task document
+func decodeTask(data string) (*Task, error) {
+	var t Task
+	if err := json.Unmarshal([]byte(data), &t); err != nil {
+		return nil, fmt.Errorf("failed to parse task: %w", err)
+	}
+	return &t, nil
+}
@@ -0,0 +1,131 @@
+package task
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newTestSQLiteStore returns a SQLiteStore in a temporary directory
+func newTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSQLiteImportFileStore(t *testing.T) {
+	fs := newTestStore(t)
+	for _, desc := range []string{"one", "two", "three"} {
+		if _, err := fs.Add(&Task{Description: desc, Status: "pending", Priority: "medium", Tags: []string{"home"}}); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	// Task 2 is gone, and ID 3 was the last one given out
+	if err := fs.Delete(2); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := fs.Update(&Task{ID: 3, Description: "three", Status: "pending", Priority: "high", DependsOn: []int{1}}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	s := newTestSQLiteStore(t)
+	n, err := s.ImportFileStore(fs)
+	if err != nil {
+		t.Fatalf("ImportFileStore: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("ImportFileStore copied %d tasks, want 2", n)
+	}
+
+	one, err := s.GetByID(1)
+	if err != nil || one.Description != "one" || !s.HasTag(1, "home") {
+		t.Errorf("GetByID(1) = %+v, %v", one, err)
+	}
+	three, err := s.GetByID(3)
+	if err != nil || !s.IsHighPriority(3) || len(three.DependsOn) != 1 || three.DependsOn[0] != 1 {
+		t.Errorf("GetByID(3) = %+v, %v", three, err)
+	}
+	if _, err := s.GetByID(2); err == nil {
+		t.Error("the deleted task 2 was migrated")
+	}
+
+	// The IDs of deleted tasks are not given out again
+	id, err := s.Add(&Task{Description: "four", Status: "pending", Priority: "low"})
+	if err != nil || id != 4 {
+		t.Errorf("Add after the migration = %d, %v, want ID 4", id, err)
+	}
+
+	if _, err := s.ImportFileStore(fs); err == nil || !strings.Contains(err.Error(), "already contains") {
+		t.Errorf("second ImportFileStore error = %v, want one about existing tasks", err)
+	}
+}
+
+func TestSQLiteRejectsRelationCycles(t *testing.T) {
+	s := newTestSQLiteStore(t)
+	for _, desc := range []string{"a", "b", "c"} {
+		if _, err := s.Add(&Task{Description: desc, Status: "pending", Priority: "medium"}); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	// 1 depends on 2, which depends on 3; 3 is a subtask of 1
+	for _, update := range []*Task{
+		{ID: 1, Description: "a", Status: "pending", Priority: "medium", DependsOn: []int{2}},
+		{ID: 2, Description: "b", Status: "pending", Priority: "medium", DependsOn: []int{3}},
+		{ID: 3, Description: "c", Status: "pending", Priority: "medium", ParentID: 1},
+	} {
+		if err := s.Update(update); err != nil {
+			t.Fatalf("Update(%d): %v", update.ID, err)
+		}
+	}
+	before := make(map[int]*Task)
+	for id := 1; id <= 3; id++ {
+		task, err := s.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before[id] = task
+	}
+
+	tests := []struct {
+		name   string
+		update *Task
+		want   string
+	}{
+		{"self dependency", &Task{ID: 3, Description: "c", Status: "pending", Priority: "medium", ParentID: 1, DependsOn: []int{3}}, "cannot depend on itself"},
+		{"dependency cycle", &Task{ID: 3, Description: "c", Status: "pending", Priority: "medium", ParentID: 1, DependsOn: []int{1}}, "dependency cycle"},
+		{"missing dependency", &Task{ID: 3, Description: "c", Status: "pending", Priority: "medium", ParentID: 1, DependsOn: []int{9}}, "does not exist"},
+		{"ancestor cycle", &Task{ID: 1, Description: "a", Status: "pending", Priority: "medium", DependsOn: []int{2}, ParentID: 3}, "own ancestor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Update(tt.update); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Update error = %v, want one containing %q", err, tt.want)
+			}
+
+			// The transaction was rolled back
+			got, err := s.GetByID(tt.update.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := before[tt.update.ID]; got.ParentID != want.ParentID || !slices.Equal(got.DependsOn, want.DependsOn) {
+				t.Errorf("task %d was saved with dependencies %v and parent %d", got.ID, got.DependsOn, got.ParentID)
+			}
+		})
+	}
+
+	d := &Task{Description: "d", Status: "pending", Priority: "low"}
+	if _, err := s.Import([]*Task{d}, func() error {
+		d.DependsOn = []int{d.ID}
+		return nil
+	}); err == nil {
+		t.Error("Import accepted a task depending on itself")
+	}
+	if tasks, err := s.GetAll(); err != nil || len(tasks) != 3 {
+		t.Errorf("GetAll after the refused import = %d tasks, %v, want 3", len(tasks), err)
+	}
+}
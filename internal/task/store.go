@@ -2,16 +2,35 @@ package task
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 )
 
 // FileStore implements the Store interface using file-based storage
 type FileStore struct {
 	filePath string
+	lockPath string
+	journal  *Journal
+}
+
+var (
+	_ Store        = (*FileStore)(nil)
+	_ HistoryStore = (*FileStore)(nil)
+)
+
+// ErrNotFound is wrapped by the errors stores return for task IDs that do not
+// exist
+var ErrNotFound = errors.New("not found")
+
+// notFound returns the error for a task ID that does not exist
+func notFound(id int) error {
+	return fmt.Errorf("task with ID %d %w", id, ErrNotFound)
 }
 
 // TaskData represents the structure stored in the JSON file
@@ -19,6 +38,9 @@ type TaskData struct {
 	Tasks    []*Task   `json:"tasks"`
 	NextID   int       `json:"next_id"`
 	Modified time.Time `json:"modified"`
+
+	// JournalSeq is the sequence number of the last journal entry
+	JournalSeq int `json:"journal_seq,omitempty"`
 }
 
 // NewFileStore creates a new FileStore instance
@@ -28,52 +50,66 @@ func NewFileStore() (*FileStore, error) {
 		return nil, fmt.Errorf("failed to get home directory: %w", err)
 	}
 
-	dataDir := filepath.Join(home, ".taskman")
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
+	return NewFileStoreAt(filepath.Join(home, ".taskman", "tasks.json"))
+}
+
+// NewFileStoreAt creates a FileStore backed by the given tasks file
+func NewFileStoreAt(filePath string) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	filePath := filepath.Join(dataDir, "tasks.json")
-
 	store := &FileStore{
 		filePath: filePath,
+		lockPath: filePath + ".lock",
+		journal:  NewJournal(strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".journal.jsonl"),
 	}
 
 	// Initialize file if it doesn't exist
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		if err := store.save(&TaskData{NextID: 1, Modified: time.Now()}); err != nil {
-			return nil, fmt.Errorf("failed to initialize tasks file: %w", err)
+	err := store.withLock(true, func() error {
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			return store.save(&TaskData{NextID: 1, Modified: time.Now()})
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tasks file: %w", err)
 	}
 
 	return store, nil
 }
 
+// Path returns the location of the tasks file
+func (fs *FileStore) Path() string {
+	return fs.filePath
+}
+
+// Journal returns the journal recording the store's operations
+func (fs *FileStore) Journal() *Journal {
+	return fs.journal
+}
+
 // Add adds a new task and returns its ID
 func (fs *FileStore) Add(task *Task) (int, error) {
-	data, err := fs.load()
+	err := fs.modify(OpAdd, func(data *TaskData) error {
+		task.ID = data.NextID
+		task.CreatedAt = time.Now()
+		task.UpdatedAt = time.Now()
+
+		data.Tasks = append(data.Tasks, task)
+		data.NextID++
+		return nil
+	})
 	if err != nil {
 		return 0, err
 	}
 
-	task.ID = data.NextID
-	task.CreatedAt = time.Now()
-	task.UpdatedAt = time.Now()
-
-	data.Tasks = append(data.Tasks, task)
-	data.NextID++
-	data.Modified = time.Now()
-
-	if err := fs.save(data); err != nil {
-		return 0, err
-	}
-
 	return task.ID, nil
 }
 
 // GetAll returns all tasks
 func (fs *FileStore) GetAll() ([]*Task, error) {
-	data, err := fs.load()
+	data, err := fs.read()
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +124,7 @@ func (fs *FileStore) GetAll() ([]*Task, error) {
 
 // GetByID returns a task by its ID
 func (fs *FileStore) GetByID(id int) (*Task, error) {
-	data, err := fs.load()
+	data, err := fs.read()
 	if err != nil {
 		return nil, err
 	}
@@ -99,55 +135,339 @@ func (fs *FileStore) GetByID(id int) (*Task, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("task with ID %d not found", id)
+	return nil, notFound(id)
 }
 
 // Update updates an existing task
 func (fs *FileStore) Update(updatedTask *Task) error {
-	data, err := fs.load()
-	if err != nil {
-		return err
-	}
+	return fs.modify(OpUpdate, func(data *TaskData) error {
+		for i, task := range data.Tasks {
+			if task.ID == updatedTask.ID {
+				updatedTask.UpdatedAt = time.Now()
+				data.Tasks[i] = updatedTask
+				return nil
+			}
+		}
+		return notFound(updatedTask.ID)
+	})
+}
+
+// Delete removes a task by its ID
+func (fs *FileStore) Delete(id int) error {
+	return fs.modify(OpDelete, func(data *TaskData) error {
+		for i, task := range data.Tasks {
+			if task.ID == id {
+				data.Tasks = append(data.Tasks[:i], data.Tasks[i+1:]...)
+				changed := append(dropDependency(data.Tasks, id), promoteChildren(data.Tasks, task)...)
+				for _, t := range changed {
+					t.UpdatedAt = time.Now()
+				}
+				return nil
+			}
+		}
+		return notFound(id)
+	})
+}
 
-	for i, task := range data.Tasks {
-		if task.ID == updatedTask.ID {
-			updatedTask.UpdatedAt = time.Now()
-			data.Tasks[i] = updatedTask
-			data.Modified = time.Now()
-			return fs.save(data)
+// Complete marks a task as completed. Completing a recurring task adds the
+// next occurrence of its series.
+func (fs *FileStore) Complete(id int) error {
+	return fs.modify(OpComplete, func(data *TaskData) error {
+		for _, task := range data.Tasks {
+			if task.ID != id {
+				continue
+			}
+			if task.IsCompleted() {
+				return nil
+			}
+
+			task.MarkCompleted()
+			first := IndexTasks(data.Tasks)[task.SeriesID()]
+			next, err := task.NextOccurrence(time.Now(), first)
+			if err != nil {
+				return err
+			}
+			if next != nil {
+				next.ID = data.NextID
+				next.CreatedAt = time.Now()
+				next.UpdatedAt = time.Now()
+				data.Tasks = append(data.Tasks, next)
+				data.NextID++
+			}
+			return nil
 		}
+		return notFound(id)
+	})
+}
+
+// MarkPending marks a task as pending
+func (fs *FileStore) MarkPending(id int) error {
+	return fs.MarkStatus(id, StatusPending)
+}
+
+// MarkTodo marks a task as todo
+func (fs *FileStore) MarkTodo(id int) error {
+	return fs.MarkStatus(id, StatusTodo)
+}
+
+// MarkInProgress marks a task as in progress
+func (fs *FileStore) MarkInProgress(id int) error {
+	return fs.MarkStatus(id, StatusInProgress)
+}
+
+// MarkDeleted marks a task as deleted without removing it
+func (fs *FileStore) MarkDeleted(id int) error {
+	return fs.MarkStatus(id, StatusDeleted)
+}
+
+// MarkArchived marks a task as archived
+func (fs *FileStore) MarkArchived(id int) error {
+	return fs.MarkStatus(id, StatusArchived)
+}
+
+// MarkCompleted marks a task as completed
+func (fs *FileStore) MarkCompleted(id int) error {
+	return fs.Complete(id)
+}
+
+// MarkStatus sets the status of a task
+func (fs *FileStore) MarkStatus(id int, status string) error {
+	if status == StatusCompleted {
+		return fs.Complete(id)
 	}
+	return fs.modifyTask(OpStatus, id, func(t *Task) error {
+		t.MarkStatus(status)
+		return nil
+	})
+}
 
-	return fmt.Errorf("task with ID %d not found", updatedTask.ID)
+// IsCompleted returns true if the task exists and is completed
+func (fs *FileStore) IsCompleted(id int) bool {
+	t, err := fs.GetByID(id)
+	return err == nil && t.IsCompleted()
 }
 
-// Delete removes a task by its ID
-func (fs *FileStore) Delete(id int) error {
-	data, err := fs.load()
-	if err != nil {
+// IsPending returns true if the task exists and is pending
+func (fs *FileStore) IsPending(id int) bool {
+	t, err := fs.GetByID(id)
+	return err == nil && t.IsPending()
+}
+
+// IsHighPriority returns true if the task exists and has high priority
+func (fs *FileStore) IsHighPriority(id int) bool {
+	t, err := fs.GetByID(id)
+	return err == nil && t.IsHighPriority()
+}
+
+// IsLowPriority returns true if the task exists and has low priority
+func (fs *FileStore) IsLowPriority(id int) bool {
+	t, err := fs.GetByID(id)
+	return err == nil && t.IsLowPriority()
+}
+
+// IsMediumPriority returns true if the task exists and has medium priority
+func (fs *FileStore) IsMediumPriority(id int) bool {
+	t, err := fs.GetByID(id)
+	return err == nil && t.IsMediumPriority()
+}
+
+// HasTag returns true if the task exists and has the specified tag
+func (fs *FileStore) HasTag(id int, tag string) bool {
+	t, err := fs.GetByID(id)
+	return err == nil && t.HasTag(tag)
+}
+
+// AddTag adds a tag to a task
+func (fs *FileStore) AddTag(id int, tag string) error {
+	return fs.modifyTask(OpTag, id, func(t *Task) error {
+		t.AddTag(tag)
+		return nil
+	})
+}
+
+// RemoveTag removes a tag from a task
+func (fs *FileStore) RemoveTag(id int, tag string) error {
+	return fs.modifyTask(OpTag, id, func(t *Task) error {
+		t.RemoveTag(tag)
+		return nil
+	})
+}
+
+// RenameProject moves every task in the project, or in one of its
+// subprojects, to the new project and returns how many tasks were moved
+func (fs *FileStore) RenameProject(from, to string) (int, error) {
+	moved := 0
+	err := fs.modify(OpProject, func(data *TaskData) error {
+		for _, t := range data.Tasks {
+			if project, ok := MoveProject(t.Project, from, to); ok {
+				t.Project = project
+				t.UpdatedAt = time.Now()
+				moved++
+			}
+		}
+		return nil
+	})
+	return moved, err
+}
+
+// StartTimer starts tracking time on a task, stopping the timer running on
+// any other task, and returns the ID of that task or 0
+func (fs *FileStore) StartTimer(id int) (int, error) {
+	stoppedID := 0
+	err := fs.modify(OpTimer, func(data *TaskData) error {
+		stopped, err := startTimer(data.Tasks, id, time.Now())
+		if stopped != nil {
+			stoppedID = stopped.ID
+		}
 		return err
-	}
+	})
+	return stoppedID, err
+}
 
-	for i, task := range data.Tasks {
-		if task.ID == id {
-			data.Tasks = append(data.Tasks[:i], data.Tasks[i+1:]...)
-			data.Modified = time.Now()
-			return fs.save(data)
+// StopTimer stops the timer of a task, or the running one for id 0, and
+// returns the ID of the task it was running on
+func (fs *FileStore) StopTimer(id int) (int, error) {
+	stoppedID := 0
+	err := fs.modify(OpTimer, func(data *TaskData) error {
+		stopped, err := stopTimer(data.Tasks, id, time.Now())
+		if stopped != nil {
+			stoppedID = stopped.ID
 		}
+		return err
+	})
+	return stoppedID, err
+}
+
+// Import adds tasks in one operation, keeping their creation and update times
+// when set, and returns their IDs. Tasks that already have an ID replace the
+// stored task with that ID. Once every task has its ID, link is called, if not
+// nil, to set relations between them.
+func (fs *FileStore) Import(tasks []*Task, link func() error) ([]int, error) {
+	var ids []int
+	err := fs.modify(OpImport, func(data *TaskData) error {
+		now := time.Now()
+		for _, t := range tasks {
+			if t.ID != 0 {
+				i := slices.IndexFunc(data.Tasks, func(s *Task) bool { return s.ID == t.ID })
+				if i < 0 {
+					return notFound(t.ID)
+				}
+				data.Tasks[i] = t
+				ids = append(ids, t.ID)
+				continue
+			}
+
+			t.ID = data.NextID
+			if t.CreatedAt.IsZero() {
+				t.CreatedAt = now
+			}
+			if t.UpdatedAt.IsZero() {
+				t.UpdatedAt = now
+			}
+
+			data.Tasks = append(data.Tasks, t)
+			data.NextID++
+			ids = append(ids, t.ID)
+		}
+
+		if link != nil {
+			return link()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return ids, nil
+}
 
-	return fmt.Errorf("task with ID %d not found", id)
+// modifyTask applies fn to a single task within one load-modify-save cycle
+func (fs *FileStore) modifyTask(op string, id int, fn func(t *Task) error) error {
+	return fs.modify(op, func(data *TaskData) error {
+		for _, task := range data.Tasks {
+			if task.ID == id {
+				return fn(task)
+			}
+		}
+		return notFound(id)
+	})
 }
 
-// Complete marks a task as completed
-func (fs *FileStore) Complete(id int) error {
-	task, err := fs.GetByID(id)
-	if err != nil {
+// read loads the task data while holding a shared lock
+func (fs *FileStore) read() (*TaskData, error) {
+	var data *TaskData
+	err := fs.withLock(false, func() error {
+		var err error
+		data, err = fs.load()
 		return err
+	})
+	return data, err
+}
+
+// modify runs a load-modify-save cycle while holding an exclusive lock.
+// The data is only written back if fn succeeds, and the tasks it changed are
+// recorded in the journal under op.
+func (fs *FileStore) modify(op string, fn func(data *TaskData) error) error {
+	return fs.withLock(true, func() error {
+		data, err := fs.load()
+		if err != nil {
+			return err
+		}
+
+		before, err := snapshotTasks(data.Tasks)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(data); err != nil {
+			return err
+		}
+		if err := checkRelations(data.Tasks); err != nil {
+			return err
+		}
+
+		after, err := snapshotTasks(data.Tasks)
+		if err != nil {
+			return err
+		}
+		changes, err := diffTasks(before, after)
+		if err != nil {
+			return err
+		}
+
+		var entry *JournalEntry
+		if len(changes) > 0 {
+			data.JournalSeq++
+			entry = &JournalEntry{Seq: data.JournalSeq, Time: time.Now(), Op: op, Changes: changes}
+		}
+
+		data.Modified = time.Now()
+		if err := fs.save(data); err != nil {
+			return err
+		}
+
+		if entry != nil {
+			return fs.journal.Append(entry)
+		}
+		return nil
+	})
+}
+
+// withLock runs fn while holding an advisory lock on the lock file, so that
+// concurrent taskman processes serialize their access to the tasks file.
+func (fs *FileStore) withLock(exclusive bool, fn func() error) error {
+	lock, err := os.OpenFile(fs.lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
 	}
+	defer lock.Close()
 
-	task.MarkCompleted()
-	return fs.Update(task)
+	if err := lockFile(lock, exclusive); err != nil {
+		return fmt.Errorf("failed to lock tasks file: %w", err)
+	}
+	defer unlockFile(lock)
+
+	return fn()
 }
 
 // load reads the task data from file
@@ -165,16 +485,53 @@ func (fs *FileStore) load() (*TaskData, error) {
 	return &data, nil
 }
 
-// save writes the task data to file
+// save atomically replaces the tasks file: the data is written to a temporary
+// file in the same directory, flushed to disk and then renamed into place, so a
+// crash never leaves a truncated tasks file behind.
 func (fs *FileStore) save(data *TaskData) error {
 	jsonData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal task data: %w", err)
 	}
 
-	if err := os.WriteFile(fs.filePath, jsonData, 0644); err != nil {
+	dir := filepath.Dir(fs.filePath)
+	tmp, err := os.CreateTemp(dir, filepath.Base(fs.filePath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary tasks file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(jsonData); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write tasks file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync tasks file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
 		return fmt.Errorf("failed to write tasks file: %w", err)
 	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		return fmt.Errorf("failed to write tasks file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, fs.filePath); err != nil {
+		return fmt.Errorf("failed to replace tasks file: %w", err)
+	}
 
+	syncDir(dir)
 	return nil
 }
+
+// syncDir flushes a directory so a rename inside it survives a crash. Errors
+// are ignored since not every platform supports syncing directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
@@ -0,0 +1,155 @@
+package task
+
+import (
+	"fmt"
+	"time"
+)
+
+// Undo reverts the last n operations that are still in effect, most recent
+// first, and returns the undo entries it recorded
+func (fs *FileStore) Undo(n int, force bool) ([]*JournalEntry, error) {
+	return fs.replay(func(applied, undone []*JournalEntry) ([]*JournalEntry, error) {
+		if len(applied) == 0 {
+			return nil, fmt.Errorf("nothing to undo")
+		}
+		if n > len(applied) {
+			n = len(applied)
+		}
+
+		var targets []*JournalEntry
+		for i := len(applied) - 1; i >= len(applied)-n; i-- {
+			targets = append(targets, applied[i])
+		}
+		return targets, nil
+	}, OpUndo, force)
+}
+
+// UndoTask reverts the last operation in effect that touched the task
+func (fs *FileStore) UndoTask(id int, force bool) (*JournalEntry, error) {
+	entries, err := fs.replay(func(applied, undone []*JournalEntry) ([]*JournalEntry, error) {
+		for i := len(applied) - 1; i >= 0; i-- {
+			if applied[i].Touches(id) {
+				return []*JournalEntry{applied[i]}, nil
+			}
+		}
+		return nil, fmt.Errorf("nothing to undo for task with ID %d", id)
+	}, OpUndo, force)
+	if err != nil {
+		return nil, err
+	}
+	return entries[0], nil
+}
+
+// Redo reapplies the last n undone operations, most recently undone first, and
+// returns the redo entries it recorded
+func (fs *FileStore) Redo(n int, force bool) ([]*JournalEntry, error) {
+	return fs.replay(func(applied, undone []*JournalEntry) ([]*JournalEntry, error) {
+		if len(undone) == 0 {
+			return nil, fmt.Errorf("nothing to redo")
+		}
+		if n > len(undone) {
+			n = len(undone)
+		}
+
+		var targets []*JournalEntry
+		for i := len(undone) - 1; i >= len(undone)-n; i-- {
+			targets = append(targets, undone[i])
+		}
+		return targets, nil
+	}, OpRedo, force)
+}
+
+// History returns the journal entries touching the task, or every entry when
+// id is 0, oldest first
+func (fs *FileStore) History(id int) ([]*JournalEntry, error) {
+	var entries []*JournalEntry
+	err := fs.withLock(false, func() error {
+		var err error
+		entries, err = fs.journal.Entries()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, undone := journalState(entries)
+	undoneSeqs := make(map[int]bool)
+	for _, e := range undone {
+		undoneSeqs[e.Seq] = true
+	}
+
+	var history []*JournalEntry
+	for _, e := range entries {
+		if id != 0 && !e.Touches(id) {
+			continue
+		}
+		e.Undone = undoneSeqs[e.Seq]
+		history = append(history, e)
+	}
+
+	return history, nil
+}
+
+// replay picks journal entries with pick and reverts (op undo) or reapplies
+// (op redo) them in order, recording one journal entry per reverted or
+// reapplied operation. Everything happens under a single exclusive lock.
+func (fs *FileStore) replay(pick func(applied, undone []*JournalEntry) ([]*JournalEntry, error), op string, force bool) ([]*JournalEntry, error) {
+	var recorded []*JournalEntry
+
+	err := fs.withLock(true, func() error {
+		data, err := fs.load()
+		if err != nil {
+			return err
+		}
+
+		entries, err := fs.journal.Entries()
+		if err != nil {
+			return err
+		}
+
+		targets, err := pick(journalState(entries))
+		if err != nil {
+			return err
+		}
+
+		for _, target := range targets {
+			if err := applyChanges(data, target.Seq, target.Changes, op == OpUndo, force); err != nil {
+				return err
+			}
+
+			changes := target.Changes
+			if op == OpUndo {
+				changes = make([]Change, len(target.Changes))
+				for i, c := range target.Changes {
+					changes[i] = Change{TaskID: c.TaskID, Before: c.After, After: c.Before}
+				}
+			}
+
+			data.JournalSeq++
+			recorded = append(recorded, &JournalEntry{
+				Seq:     data.JournalSeq,
+				Time:    time.Now(),
+				Op:      op,
+				Ref:     target.Seq,
+				Changes: changes,
+			})
+		}
+
+		// Reverting an add or a change can leave other tasks pointing at a
+		// task that no longer exists; refuse rather than save a broken store
+		if err := checkRelations(data.Tasks); err != nil {
+			return fmt.Errorf("cannot %s: %w", op, err)
+		}
+
+		data.Modified = time.Now()
+		if err := fs.save(data); err != nil {
+			return err
+		}
+		return fs.journal.Append(recorded...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return recorded, nil
+}
@@ -0,0 +1,66 @@
+package task
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUndoRefusesToOrphanRelatedTasks(t *testing.T) {
+	tests := []struct {
+		name  string
+		child *Task
+		want  string
+	}{
+		{"parent", &Task{Description: "child", Status: "pending", Priority: "medium", ParentID: 1}, "task 2"},
+		{"dependency", &Task{Description: "child", Status: "pending", Priority: "medium", DependsOn: []int{1}}, "task 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newTestStore(t)
+			if _, err := fs.Add(&Task{Description: "parent", Status: "pending", Priority: "medium"}); err != nil {
+				t.Fatalf("Add parent: %v", err)
+			}
+			if _, err := fs.Add(tt.child); err != nil {
+				t.Fatalf("Add child: %v", err)
+			}
+
+			_, err := fs.UndoTask(1, false)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("UndoTask(1) error = %v, want one naming %s", err, tt.want)
+			}
+
+			tasks, err := fs.GetAll()
+			if err != nil {
+				t.Fatalf("GetAll: %v", err)
+			}
+			if len(tasks) != 2 {
+				t.Fatalf("got %d tasks after the refused undo, want 2", len(tasks))
+			}
+			if _, err := fs.Add(&Task{Description: "later", Status: "pending", Priority: "low"}); err != nil {
+				t.Fatalf("Add after the refused undo: %v", err)
+			}
+		})
+	}
+}
+
+func TestUndoRedoAdd(t *testing.T) {
+	fs := newTestStore(t)
+	if _, err := fs.Add(&Task{Description: "one", Status: "pending", Priority: "medium"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	if _, err := fs.Undo(1, false); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if _, err := fs.GetByID(1); err == nil {
+		t.Fatal("task 1 still exists after undoing its add")
+	}
+
+	if _, err := fs.Redo(1, false); err != nil {
+		t.Fatalf("Redo: %v", err)
+	}
+	if got, err := fs.GetByID(1); err != nil || got.Description != "one" {
+		t.Fatalf("GetByID(1) after redo = %v, %v", got, err)
+	}
+}
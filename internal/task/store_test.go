@@ -0,0 +1,190 @@
+package task
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// Environment of a re-executed test binary: the driver and the store it adds to
+const (
+	storeHelperDriverEnv = "TASKMAN_TEST_DRIVER"
+	storeHelperEnv       = "TASKMAN_TEST_STORE"
+)
+
+// testDrivers lists the store backends the shared store tests run against
+var testDrivers = []struct {
+	name string
+	file string
+	open func(path string) (Store, error)
+}{
+	{"json", "tasks.json", func(path string) (Store, error) { return NewFileStoreAt(path) }},
+	{"sqlite", "tasks.db", func(path string) (Store, error) { return NewSQLiteStore(path) }},
+}
+
+// newTestStore returns a FileStore in a temporary directory
+func newTestStore(t *testing.T) *FileStore {
+	t.Helper()
+	fs, err := NewFileStoreAt(filepath.Join(t.TempDir(), "tasks.json"))
+	if err != nil {
+		t.Fatalf("NewFileStoreAt: %v", err)
+	}
+	return fs
+}
+
+// openTestStore opens a store with open and closes it when the test ends
+func openTestStore(t *testing.T, open func(path string) (Store, error), path string) Store {
+	t.Helper()
+	store, err := open(path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	closeStore(t, store)
+	return store
+}
+
+// closeStore closes the store when the test ends, if it holds resources
+func closeStore(t *testing.T, store Store) {
+	if c, ok := store.(io.Closer); ok {
+		t.Cleanup(func() { c.Close() })
+	}
+}
+
+// checkDistinctIDs checks that the store holds n tasks with distinct IDs and
+// gives the next task ID n+1
+func checkDistinctIDs(t *testing.T, store Store, n int) {
+	t.Helper()
+	tasks, err := store.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+
+	if len(tasks) != n {
+		t.Fatalf("got %d tasks, want %d", len(tasks), n)
+	}
+	ids := make(map[int]bool)
+	for _, task := range tasks {
+		if ids[task.ID] {
+			t.Fatalf("ID %d was given out twice", task.ID)
+		}
+		ids[task.ID] = true
+	}
+
+	id, err := store.Add(&Task{Description: "next", Status: "pending", Priority: "low"})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if id != n+1 {
+		t.Errorf("next task got ID %d, want %d", id, n+1)
+	}
+}
+
+func TestStoreConcurrentAdds(t *testing.T) {
+	for _, driver := range testDrivers {
+		t.Run(driver.name, func(t *testing.T) {
+			const n = 50
+			path := filepath.Join(t.TempDir(), driver.file)
+			store := openTestStore(t, driver.open, path)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, n)
+			for i := 0; i < n; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					// Every goroutine has its own store, as separate commands would
+					store, err := driver.open(path)
+					if err != nil {
+						errs <- err
+						return
+					}
+					closeStore(t, store)
+					_, err = store.Add(&Task{Description: fmt.Sprintf("task %d", i), Status: "pending", Priority: "medium"})
+					errs <- err
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				if err != nil {
+					t.Fatalf("Add: %v", err)
+				}
+			}
+			checkDistinctIDs(t, store, n)
+		})
+	}
+}
+
+func TestStoreMultiProcessAdds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts processes")
+	}
+	for _, driver := range testDrivers {
+		t.Run(driver.name, func(t *testing.T) {
+			const n = 8
+			path := filepath.Join(t.TempDir(), driver.file)
+			// The store exists before the processes start, as after the first command
+			store := openTestStore(t, driver.open, path)
+
+			cmds := make([]*exec.Cmd, n)
+			outs := make([]bytes.Buffer, n)
+			for i := range cmds {
+				cmds[i] = exec.Command(os.Args[0], "-test.run=^TestStoreHelperProcess$")
+				cmds[i].Env = append(os.Environ(), storeHelperDriverEnv+"="+driver.name, storeHelperEnv+"="+path)
+				cmds[i].Stdout, cmds[i].Stderr = &outs[i], &outs[i]
+				if err := cmds[i].Start(); err != nil {
+					t.Fatalf("failed to start process: %v", err)
+				}
+			}
+			for i, cmd := range cmds {
+				if err := cmd.Wait(); err != nil {
+					t.Fatalf("process failed: %v\n%s", err, outs[i].String())
+				}
+			}
+			checkDistinctIDs(t, store, n)
+		})
+	}
+}
+
+// TestStoreHelperProcess is run by TestStoreMultiProcessAdds in separate
+// processes, each adding one task
+func TestStoreHelperProcess(t *testing.T) {
+	path := os.Getenv(storeHelperEnv)
+	if path == "" {
+		t.Skip("only run by TestStoreMultiProcessAdds")
+	}
+
+	for _, driver := range testDrivers {
+		if driver.name != os.Getenv(storeHelperDriverEnv) {
+			continue
+		}
+		store := openTestStore(t, driver.open, path)
+		if _, err := store.Add(&Task{Description: fmt.Sprintf("process %d", os.Getpid()), Status: "pending", Priority: "medium"}); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+}
+
+func TestFileStoreSaveLeavesNoTemporaryFiles(t *testing.T) {
+	fs := newTestStore(t)
+	for i := 0; i < 3; i++ {
+		if _, err := fs.Add(&Task{Description: "task", Status: "pending", Priority: "low"}); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	tmps, err := filepath.Glob(filepath.Join(filepath.Dir(fs.Path()), "tasks.json.tmp-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tmps) > 0 {
+		t.Errorf("temporary files left behind: %v", tmps)
+	}
+	checkDistinctIDs(t, fs, 3)
+}
@@ -0,0 +1,44 @@
+package task
+
+// Summary counts tasks by status and priority
+type Summary struct {
+	Total     int `json:"total"`
+	Pending   int `json:"pending"`
+	Completed int `json:"completed"`
+	High      int `json:"high"`
+	Medium    int `json:"medium"`
+	Low       int `json:"low"`
+}
+
+// Summarize counts the tasks. Pending counts every task that is still to be
+// done: todo, pending and in progress.
+func Summarize(tasks []*Task) Summary {
+	s := Summary{Total: len(tasks)}
+	for _, t := range tasks {
+		switch t.Status {
+		case StatusPending, StatusTodo, StatusInProgress:
+			s.Pending++
+		case StatusCompleted:
+			s.Completed++
+		}
+
+		switch t.Priority {
+		case PriorityHigh:
+			s.High++
+		case PriorityMedium:
+			s.Medium++
+		case PriorityLow:
+			s.Low++
+		}
+	}
+	return s
+}
+
+// Progress returns the share of completed tasks in percent, and false when
+// there are no pending or completed tasks
+func (s Summary) Progress() (int, bool) {
+	if s.Pending+s.Completed == 0 {
+		return 0, false
+	}
+	return s.Completed * 100 / (s.Pending + s.Completed), true
+}
@@ -2,6 +2,9 @@ package task
 
 import (
 	"time"
+
+	"github.com/vkhangstack/taskman/internal/dates"
+	"github.com/vkhangstack/taskman/internal/recur"
 )
 
 var (
@@ -18,14 +21,37 @@ var (
 
 // Task represents a task item
 type Task struct {
-	ID          int        `json:"id"`
-	Description string     `json:"description"`
-	Status      string     `json:"status"`   // pending, completed
-	Priority    string     `json:"priority"` // low, medium, high
-	Tags        []string   `json:"tags"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ID          int          `json:"id"`
+	Description string       `json:"description"`
+	Status      string       `json:"status"`   // pending, completed
+	Priority    string       `json:"priority"` // low, medium, high
+	Tags        []string     `json:"tags"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	Due         *time.Time   `json:"due,omitempty"`
+	Recur       string       `json:"recur,omitempty"`        // recurrence rule, see package recur
+	RecurParent int          `json:"recur_parent,omitempty"` // ID of the first task of the series
+	Transitions []Transition `json:"transitions,omitempty"`
+	DependsOn   []int        `json:"depends_on,omitempty"` // IDs of tasks that must be completed first
+	ParentID    int          `json:"parent_id,omitempty"`  // ID of the task this is a subtask of
+	Project     string       `json:"project,omitempty"`    // dotted hierarchy such as work.backend
+	TimeLog     []TimeEntry  `json:"time_log,omitempty"`
+	UUID        string       `json:"uuid,omitempty"` // identity in other task managers, see SyncUUID
+	Annotations []Annotation `json:"annotations,omitempty"`
+}
+
+// Transition records a status change of a task
+type Transition struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	At   time.Time `json:"at"`
+}
+
+// Annotation is a timestamped note on a task
+type Annotation struct {
+	Entry       time.Time `json:"entry"`
+	Description string    `json:"description"`
 }
 
 // Store defines the interface for task storage
@@ -51,6 +77,18 @@ type Store interface {
 	HasTag(id int, tag string) bool
 	AddTag(id int, tag string) error
 	RemoveTag(id int, tag string) error
+	RenameProject(from, to string) (int, error)
+	// StartTimer starts tracking time on a task, stopping the timer running
+	// on any other task, and returns the ID of that task or 0
+	StartTimer(id int) (int, error)
+	// StopTimer stops the timer of a task, or the running one for id 0, and
+	// returns the ID of the task it was running on
+	StopTimer(id int) (int, error)
+	// Import adds tasks in one operation, keeping their creation and update
+	// times when set, and returns their IDs. Tasks that already have an ID
+	// replace the stored task with that ID. Once every task has its ID, link is
+	// called, if not nil, to set relations between them such as dependencies.
+	Import(tasks []*Task, link func() error) ([]int, error)
 }
 
 // IsCompleted returns true if the task is completed
@@ -63,6 +101,22 @@ func (t *Task) IsPending() bool {
 	return t.Status == StatusPending
 }
 
+// IsOpen returns true if the task still needs work, i.e. it is not completed,
+// archived or deleted
+func (t *Task) IsOpen() bool {
+	return t.Status != StatusCompleted && t.Status != StatusArchived && t.Status != StatusDeleted
+}
+
+// IsOverdue returns true if the task is open and its due date has passed
+func (t *Task) IsOverdue(now time.Time) bool {
+	return t.Due != nil && t.IsOpen() && t.Due.Before(now)
+}
+
+// IsDueToday returns true if the task is open and due later today
+func (t *Task) IsDueToday(now time.Time) bool {
+	return t.Due != nil && t.IsOpen() && !t.Due.Before(now) && dates.SameDay(*t.Due, now)
+}
+
 // IsHighPriority returns true if the task has high priority
 func (t *Task) IsHighPriority() bool {
 	return t.Priority == PriorityHigh
@@ -109,37 +163,36 @@ func (t *Task) RemoveTag(tag string) {
 
 // MarkCompleted marks the task as completed
 func (t *Task) MarkCompleted() {
-	t.Status = StatusCompleted
 	now := time.Now()
+	t.setStatus(StatusCompleted, now)
 	t.CompletedAt = &now
-	t.UpdatedAt = now
+
+	// Completing a task stops its timer
+	if t.ActiveTimer() != nil {
+		t.StopTimer(now)
+	}
 }
 
 // MarkPending marks the task as pending
 func (t *Task) MarkPending() {
-	t.Status = StatusPending
+	t.setStatus(StatusPending, time.Now())
 	t.CompletedAt = nil
-	t.UpdatedAt = time.Now()
 }
 func (t *Task) MarkTodo() {
-	t.Status = StatusTodo
+	t.setStatus(StatusTodo, time.Now())
 	t.CompletedAt = nil
-	t.UpdatedAt = time.Now()
 }
 func (t *Task) MarkInProgress() {
-	t.Status = StatusInProgress
+	t.setStatus(StatusInProgress, time.Now())
 	t.CompletedAt = nil
-	t.UpdatedAt = time.Now()
 }
 func (t *Task) MarkDeleted() {
-	t.Status = StatusDeleted
+	t.setStatus(StatusDeleted, time.Now())
 	t.CompletedAt = nil
-	t.UpdatedAt = time.Now()
 }
 func (t *Task) MarkArchived() {
-	t.Status = StatusArchived
+	t.setStatus(StatusArchived, time.Now())
 	t.CompletedAt = nil
-	t.UpdatedAt = time.Now()
 }
 func (t *Task) MarkStatus(status string) {
 	switch status {
@@ -156,6 +209,116 @@ func (t *Task) MarkStatus(status string) {
 	case StatusCompleted:
 		t.MarkCompleted()
 	default:
-		t.Status = status
+		t.setStatus(status, time.Now())
+	}
+}
+
+// setStatus changes the status and records the transition
+func (t *Task) setStatus(status string, at time.Time) {
+	if t.Status != status {
+		t.Transitions = append(t.Transitions, Transition{From: t.Status, To: status, At: at})
+	}
+	t.Status = status
+	t.UpdatedAt = at
+}
+
+// StatusPeriod is a span of time a task spent in one status
+type StatusPeriod struct {
+	Status string
+	Start  time.Time
+	End    *time.Time // nil while the task is still in the status
+}
+
+// Timeline returns the periods the task spent in each status, oldest first.
+// Tasks created before transitions were recorded start in their first known status.
+func (t *Task) Timeline() []StatusPeriod {
+	initial := t.Status
+	if len(t.Transitions) > 0 {
+		initial = t.Transitions[0].From
+	}
+
+	periods := []StatusPeriod{{Status: initial, Start: t.CreatedAt}}
+	for _, tr := range t.Transitions {
+		at := tr.At
+		periods[len(periods)-1].End = &at
+		periods = append(periods, StatusPeriod{Status: tr.To, Start: tr.At})
 	}
+
+	return periods
+}
+
+// TimeInStatus returns the total time the task spent in the given status up to now
+func (t *Task) TimeInStatus(status string, now time.Time) time.Duration {
+	var total time.Duration
+	for _, p := range t.Timeline() {
+		if p.Status != status {
+			continue
+		}
+		end := now
+		if p.End != nil {
+			end = *p.End
+		}
+		total += end.Sub(p.Start)
+	}
+	return total
+}
+
+// IsRecurring returns true if completing the task spawns a next occurrence
+func (t *Task) IsRecurring() bool {
+	return t.Recur != ""
+}
+
+// SeriesID returns the ID of the first task of the task's recurring series
+func (t *Task) SeriesID() int {
+	if t.RecurParent != 0 {
+		return t.RecurParent
+	}
+	return t.ID
+}
+
+// NextOccurrence builds the next instance of a recurring task, keeping its
+// description, priority, project, tags, parent and rule. Occurrences that are
+// already in the past at now are skipped. It returns nil if the task does not
+// recur or the series has ended. The returned task has no ID yet.
+//
+// first is the first task of the series, whose due date fixes the day of
+// month of monthly and yearly rules, or nil if it no longer exists.
+func (t *Task) NextOccurrence(now time.Time, first *Task) (*Task, error) {
+	if !t.IsRecurring() {
+		return nil, nil
+	}
+
+	rule, err := recur.Parse(t.Recur)
+	if err != nil {
+		return nil, err
+	}
+
+	from := now
+	if t.Due != nil {
+		from = *t.Due
+	}
+	anchor := from
+	if first != nil && first.Due != nil {
+		anchor = *first.Due
+	}
+
+	next, ok := rule.NextFrom(anchor, from)
+	for ok && next.Before(dates.StartOfDay(now)) {
+		next, ok = rule.NextFrom(anchor, next)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	return &Task{
+		Description: t.Description,
+		Status:      StatusTodo,
+		Priority:    t.Priority,
+		Project:     t.Project,
+		Tags:        append([]string(nil), t.Tags...),
+		ParentID:    t.ParentID,
+		Due:         &next,
+		Recur:       t.Recur,
+		RecurParent: t.SeriesID(),
+	}, nil
 }
@@ -0,0 +1,75 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextOccurrenceKeepsFields(t *testing.T) {
+	due := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	done := &Task{
+		ID:          4,
+		Description: "Weekly review",
+		Status:      StatusCompleted,
+		Priority:    "high",
+		Project:     "work.backend",
+		ParentID:    2,
+		Tags:        []string{"review"},
+		Due:         &due,
+		Recur:       "weekly",
+	}
+
+	next, err := done.NextOccurrence(due, done)
+	if err != nil || next == nil {
+		t.Fatalf("NextOccurrence = %v, %v", next, err)
+	}
+
+	if next.Project != done.Project {
+		t.Errorf("Project = %q, want %q", next.Project, done.Project)
+	}
+	if next.ParentID != done.ParentID {
+		t.Errorf("ParentID = %d, want %d", next.ParentID, done.ParentID)
+	}
+	if next.Description != done.Description || next.Priority != done.Priority || next.Recur != done.Recur {
+		t.Errorf("next occurrence %+v does not keep the fields of %+v", next, done)
+	}
+	if next.RecurParent != 4 {
+		t.Errorf("RecurParent = %d, want 4", next.RecurParent)
+	}
+	if want := due.AddDate(0, 0, 7); !next.Due.Equal(want) {
+		t.Errorf("Due = %v, want %v", next.Due, want)
+	}
+}
+
+func TestCompleteMonthlyKeepsDayOfMonth(t *testing.T) {
+	fs := newTestStore(t)
+	// Start next year, so that no occurrence is in the past and skipped
+	year := time.Now().Year() + 1
+	due := time.Date(year, 1, 31, 9, 0, 0, 0, time.Local)
+	if _, err := fs.Add(&Task{Description: "Pay rent", Status: StatusTodo, Priority: "high", Due: &due, Recur: "monthly"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	// Complete three occurrences in a row; each adds the next one
+	var got []string
+	for id := 1; id <= 3; id++ {
+		if err := fs.Complete(id); err != nil {
+			t.Fatalf("Complete(%d): %v", id, err)
+		}
+		next, err := fs.GetByID(id + 1)
+		if err != nil {
+			t.Fatalf("GetByID(%d): %v", id+1, err)
+		}
+		got = append(got, next.Due.Format("01-02"))
+	}
+
+	want := []string{"02-28", "03-31", "04-30"}
+	if year%4 == 0 {
+		want[0] = "02-29"
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("occurrences = %v, want %v", got, want)
+		}
+	}
+}
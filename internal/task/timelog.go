@@ -0,0 +1,199 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vkhangstack/taskman/internal/dates"
+)
+
+// TimeEntry is an interval of work on a task. A nil End means the timer is
+// still running.
+type TimeEntry struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"`
+	Note  string     `json:"note,omitempty"`
+}
+
+// Duration returns the length of the entry, counting a running timer up to now
+func (e TimeEntry) Duration(now time.Time) time.Duration {
+	end := now
+	if e.End != nil {
+		end = *e.End
+	}
+	if end.Before(e.Start) {
+		return 0
+	}
+	return end.Sub(e.Start)
+}
+
+// IsRunning returns true if the entry's timer has not been stopped
+func (e TimeEntry) IsRunning() bool {
+	return e.End == nil
+}
+
+// ActiveTimer returns the running time entry of the task, or nil
+func (t *Task) ActiveTimer() *TimeEntry {
+	for i := range t.TimeLog {
+		if t.TimeLog[i].IsRunning() {
+			return &t.TimeLog[i]
+		}
+	}
+	return nil
+}
+
+// TimeSpent returns the total time logged on the task, including a running timer
+func (t *Task) TimeSpent(now time.Time) time.Duration {
+	var total time.Duration
+	for _, e := range t.TimeLog {
+		total += e.Duration(now)
+	}
+	return total
+}
+
+// StartTimer starts tracking time on the task. Open tasks that have not been
+// started yet move to in_progress.
+func (t *Task) StartTimer(now time.Time) error {
+	if t.ActiveTimer() != nil {
+		return fmt.Errorf("timer of task %d is already running", t.ID)
+	}
+	if !t.IsOpen() {
+		return fmt.Errorf("task %d is %s", t.ID, t.Status)
+	}
+
+	t.TimeLog = append(t.TimeLog, TimeEntry{Start: now.Truncate(time.Second)})
+	if t.Status != StatusInProgress {
+		t.setStatus(StatusInProgress, now)
+	}
+	return nil
+}
+
+// StopTimer stops the running timer of the task and returns its entry
+func (t *Task) StopTimer(now time.Time) (*TimeEntry, error) {
+	e := t.ActiveTimer()
+	if e == nil {
+		return nil, fmt.Errorf("no timer is running for task %d", t.ID)
+	}
+	end := now.Truncate(time.Second)
+	e.End = &end
+	return e, nil
+}
+
+// LogTime records d of work on the task, ending at end
+func (t *Task) LogTime(d time.Duration, end time.Time, note string) error {
+	if d <= 0 {
+		return fmt.Errorf("logged time must be positive")
+	}
+	end = end.Truncate(time.Second)
+	t.TimeLog = append(t.TimeLog, TimeEntry{Start: end.Add(-d.Truncate(time.Second)), End: &end, Note: note})
+	return nil
+}
+
+// startTimer stops any running timer among tasks and starts the timer of the
+// task with the given ID. It returns the task whose timer was stopped, if any.
+func startTimer(tasks []*Task, id int, now time.Time) (*Task, error) {
+	var target, stopped *Task
+	for _, t := range tasks {
+		if t.ID == id {
+			target = t
+		}
+	}
+	if target == nil {
+		return nil, notFound(id)
+	}
+	if target.ActiveTimer() != nil {
+		return nil, fmt.Errorf("timer of task %d is already running", id)
+	}
+
+	for _, t := range tasks {
+		if t.ActiveTimer() != nil {
+			if _, err := t.StopTimer(now); err != nil {
+				return nil, err
+			}
+			t.UpdatedAt = now
+			stopped = t
+		}
+	}
+
+	if err := target.StartTimer(now); err != nil {
+		return nil, err
+	}
+	target.UpdatedAt = now
+	return stopped, nil
+}
+
+// stopTimer stops the running timer of the task with the given ID, or of any
+// task for ID 0, and returns the task
+func stopTimer(tasks []*Task, id int, now time.Time) (*Task, error) {
+	for _, t := range tasks {
+		if (id == 0 || t.ID == id) && t.ActiveTimer() != nil {
+			if _, err := t.StopTimer(now); err != nil {
+				return nil, err
+			}
+			t.UpdatedAt = now
+			return t, nil
+		}
+	}
+	if id == 0 {
+		return nil, fmt.Errorf("no timer is running")
+	}
+	return nil, fmt.Errorf("no timer is running for task %d", id)
+}
+
+// TimeSlice is the part of a time entry that falls within a period
+type TimeSlice struct {
+	Task    *Task
+	Start   time.Time
+	End     time.Time
+	Note    string
+	Running bool
+}
+
+// Duration returns the length of the slice
+func (s TimeSlice) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// TimeSlices returns the time logged on the tasks between from and to,
+// clipped to that period and ordered by start time. Running timers count up
+// to now.
+func TimeSlices(tasks []*Task, from, to, now time.Time) []TimeSlice {
+	var slices []TimeSlice
+	for _, t := range tasks {
+		for _, e := range t.TimeLog {
+			start, end := e.Start, now
+			if e.End != nil {
+				end = *e.End
+			}
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if !end.After(start) {
+				continue
+			}
+			slices = append(slices, TimeSlice{Task: t, Start: start, End: end, Note: e.Note, Running: e.IsRunning()})
+		}
+	}
+
+	sort.SliceStable(slices, func(i, j int) bool { return slices[i].Start.Before(slices[j].Start) })
+	return slices
+}
+
+// SplitDays splits the slice at midnight so that each part falls on one day
+func (s TimeSlice) SplitDays() []TimeSlice {
+	var parts []TimeSlice
+	for {
+		midnight := dates.StartOfDay(s.Start).AddDate(0, 0, 1)
+		if !s.End.After(midnight) {
+			return append(parts, s)
+		}
+		part := s
+		part.End = midnight
+		parts = append(parts, part)
+		s.Start = midnight
+	}
+}
@@ -0,0 +1,108 @@
+package task
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// UrgencyWeights are the coefficients of the urgency score. Each factor of a
+// task is scaled to between 0 and 1 and multiplied by its weight; the score is
+// the sum. Negative weights push tasks down.
+type UrgencyWeights struct {
+	PriorityHigh   float64            `mapstructure:"priority_high"`
+	PriorityMedium float64            `mapstructure:"priority_medium"`
+	PriorityLow    float64            `mapstructure:"priority_low"`
+	Age            float64            `mapstructure:"age"`     // reached when the task is AgeMax days old
+	AgeMax         float64            `mapstructure:"age_max"` // days
+	Due            float64            `mapstructure:"due"`     // reached a week after the due date
+	InProgress     float64            `mapstructure:"in_progress"`
+	Blocked        float64            `mapstructure:"blocked"`
+	Blocking       float64            `mapstructure:"blocking"`
+	Tags           map[string]float64 `mapstructure:"tags"` // weight per tag, e.g. urgent: 5
+}
+
+// DefaultUrgencyWeights returns the weights used when none are configured
+func DefaultUrgencyWeights() UrgencyWeights {
+	return UrgencyWeights{
+		PriorityHigh:   6,
+		PriorityMedium: 3.9,
+		PriorityLow:    1.8,
+		Age:            2,
+		AgeMax:         365,
+		Due:            12,
+		InProgress:     4,
+		Blocked:        -5,
+		Blocking:       8,
+		Tags:           map[string]float64{"urgent": 5},
+	}
+}
+
+// Urgency returns the urgency score of the task. Completed and deleted tasks
+// score 0. tasks is used to find out whether the task is blocked or blocking.
+func (t *Task) Urgency(w UrgencyWeights, tasks map[int]*Task, now time.Time) float64 {
+	if !t.IsOpen() {
+		return 0
+	}
+
+	var score float64
+	switch t.Priority {
+	case PriorityHigh:
+		score += w.PriorityHigh
+	case PriorityMedium:
+		score += w.PriorityMedium
+	case PriorityLow:
+		score += w.PriorityLow
+	}
+
+	if w.AgeMax > 0 {
+		days := now.Sub(t.CreatedAt).Hours() / 24
+		score += w.Age * clamp(days/w.AgeMax, 0, 1)
+	}
+	if t.Due != nil {
+		score += w.Due * dueFactor(t.Due.Sub(now))
+	}
+	if t.Status == StatusInProgress {
+		score += w.InProgress
+	}
+	if t.IsBlocked(tasks) {
+		score += w.Blocked
+	}
+	if t.IsBlocking(tasks) {
+		score += w.Blocking
+	}
+	// Configuration keys are lowercase, so tags match regardless of case
+	for _, tag := range t.Tags {
+		score += w.Tags[strings.ToLower(tag)]
+	}
+	return score
+}
+
+// dueFactor scales the time left until the due date: 0.2 for tasks due two
+// weeks or more from now, rising linearly to 1 for tasks a week overdue
+func dueFactor(left time.Duration) float64 {
+	days := left.Hours() / 24
+	return clamp(0.2+(14-days)*0.8/21, 0.2, 1)
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return min(max(v, lo), hi)
+}
+
+// UrgencyScores returns the urgency of every task by ID
+func UrgencyScores(tasks []*Task, w UrgencyWeights, now time.Time) map[int]float64 {
+	index := IndexTasks(tasks)
+	scores := make(map[int]float64, len(tasks))
+	for _, t := range tasks {
+		scores[t.ID] = t.Urgency(w, index, now)
+	}
+	return scores
+}
+
+// SortByUrgency orders the tasks from the most to the least urgent, keeping the
+// current order of tasks with the same score
+func SortByUrgency(tasks []*Task, scores map[int]float64) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return scores[tasks[i].ID] > scores[tasks[j].ID]
+	})
+}
@@ -0,0 +1,23 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// uuidNamespace derives the UUIDs of tasks that were created in taskman
+var uuidNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/vkhangstack/taskman"))
+
+// SyncUUID returns the UUID that identifies the task in other task managers:
+// the UUID it was imported with, or one derived from its ID and creation time.
+// Derived UUIDs stay the same from one export to the next, so tasks exported
+// and imported again are recognized.
+func (t *Task) SyncUUID() string {
+	if t.UUID != "" {
+		return t.UUID
+	}
+	name := fmt.Sprintf("%d/%s", t.ID, t.CreatedAt.UTC().Format(time.RFC3339Nano))
+	return uuid.NewSHA1(uuidNamespace, []byte(name)).String()
+}
@@ -0,0 +1,280 @@
+// Package taskwarrior reads and writes the JSON of Taskwarrior's 'task export'
+// and 'task import' commands.
+//
+// Fields map onto tasks as follows:
+//
+//	uuid                  UUID, kept so that tasks can be synced back
+//	entry, modified, end  CreatedAt, UpdatedAt and CompletedAt
+//	status                pending is todo, or in_progress when the task has a
+//	                      start time; waiting is pending; completed and
+//	                      deleted are kept
+//	priority H, M, L      high, medium, low; tasks without a priority are medium
+//	project, tags, due    the same fields
+//	annotations           Annotations
+//	depends               DependsOn, resolved through the UUIDs
+//
+// Recurring task templates (status recurring) have no counterpart and are
+// rejected by ToTask.
+package taskwarrior
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// timeLayout is the ISO 8601 basic format Taskwarrior uses for dates, in UTC
+const timeLayout = "20060102T150405Z"
+
+// Task is a task as exported by Taskwarrior
+type Task struct {
+	UUID        string       `json:"uuid"`
+	Description string       `json:"description"`
+	Status      string       `json:"status"`
+	Entry       Time         `json:"entry"`
+	Modified    *Time        `json:"modified,omitempty"`
+	Start       *Time        `json:"start,omitempty"`
+	End         *Time        `json:"end,omitempty"`
+	Due         *Time        `json:"due,omitempty"`
+	Priority    string       `json:"priority,omitempty"`
+	Project     string       `json:"project,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Depends     Depends      `json:"depends,omitempty"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+}
+
+// Annotation is a note on a Taskwarrior task
+type Annotation struct {
+	Entry       Time   `json:"entry"`
+	Description string `json:"description"`
+}
+
+// Time is a time in Taskwarrior's date format
+type Time struct {
+	time.Time
+}
+
+// MarshalJSON writes the time as 20260301T120000Z
+func (t Time) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.UTC().Format(timeLayout))
+}
+
+// UnmarshalJSON reads 20260301T120000Z, and RFC 3339 as a fallback
+func (t *Time) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	for _, layout := range []string{timeLayout, time.RFC3339} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid date %q", s)
+}
+
+// Depends holds the UUIDs of the tasks a task depends on. Taskwarrior 2.6 and
+// later write them as an array, earlier versions as a comma-separated string.
+type Depends []string
+
+// UnmarshalJSON accepts both forms
+func (d *Depends) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*d = list
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid depends: %s", data)
+	}
+	*d = nil
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			*d = append(*d, id)
+		}
+	}
+	return nil
+}
+
+// Read reads a 'task export' document: a JSON array, or one JSON object per
+// line as written with rc.json.array=off
+func Read(r io.Reader) ([]Task, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read taskwarrior export: %w", err)
+	}
+
+	var tasks []Task
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &tasks); err != nil {
+			return nil, fmt.Errorf("invalid taskwarrior export: %w", err)
+		}
+		return tasks, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSuffix(bytes.TrimSpace(scanner.Bytes()), []byte(","))
+		if len(line) == 0 {
+			continue
+		}
+		var t Task
+		if err := json.Unmarshal(line, &t); err != nil {
+			return nil, fmt.Errorf("invalid taskwarrior export on line %d: %w", n, err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, scanner.Err()
+}
+
+// Write writes the tasks as a JSON array that 'task import' accepts
+func Write(w io.Writer, tasks []Task) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if tasks == nil {
+		tasks = []Task{}
+	}
+	return enc.Encode(tasks)
+}
+
+// ToTask converts a Taskwarrior task. Dependencies are left to the caller,
+// who knows which task each UUID belongs to.
+func (tw Task) ToTask() (*task.Task, error) {
+	if tw.UUID == "" {
+		return nil, fmt.Errorf("task %q has no uuid", tw.Description)
+	}
+	if strings.TrimSpace(tw.Description) == "" {
+		return nil, fmt.Errorf("task %s has no description", tw.UUID)
+	}
+
+	t := &task.Task{
+		UUID:        tw.UUID,
+		Description: tw.Description,
+		Priority:    task.PriorityMedium,
+		Project:     tw.Project,
+		Tags:        []string{},
+		CreatedAt:   tw.Entry.Time,
+		UpdatedAt:   tw.Entry.Time,
+	}
+	if err := task.ValidateProject(t.Project); err != nil {
+		return nil, err
+	}
+	if tw.Modified != nil {
+		t.UpdatedAt = tw.Modified.Time
+	}
+	if tw.Due != nil {
+		due := tw.Due.Local()
+		t.Due = &due
+	}
+	t.Tags = append(t.Tags, tw.Tags...)
+
+	switch tw.Priority {
+	case "H":
+		t.Priority = task.PriorityHigh
+	case "L":
+		t.Priority = task.PriorityLow
+	}
+
+	switch tw.Status {
+	case "pending", "":
+		t.Status = task.StatusTodo
+		if tw.Start != nil {
+			t.Status = task.StatusInProgress
+			t.Transitions = []task.Transition{{From: task.StatusTodo, To: task.StatusInProgress, At: tw.Start.Time}}
+		}
+	case "waiting":
+		t.Status = task.StatusPending
+	case "completed", "deleted":
+		t.Status = task.StatusCompleted
+		if tw.Status == "deleted" {
+			t.Status = task.StatusDeleted
+		}
+		if tw.End != nil {
+			end := tw.End.Time
+			t.CompletedAt = &end
+		}
+	default:
+		return nil, fmt.Errorf("task %s has unsupported status %q", tw.UUID, tw.Status)
+	}
+
+	for _, a := range tw.Annotations {
+		t.Annotations = append(t.Annotations, task.Annotation{Entry: a.Entry.Time, Description: a.Description})
+	}
+	return t, nil
+}
+
+// FromTask converts a task. uuidOf returns the UUID of the task with the
+// given ID, or "" for unknown tasks, so that dependencies can be written.
+func FromTask(t *task.Task, uuidOf func(id int) string) Task {
+	tw := Task{
+		UUID:        t.SyncUUID(),
+		Description: t.Description,
+		Entry:       Time{t.CreatedAt},
+		Modified:    &Time{t.UpdatedAt},
+		Project:     t.Project,
+		Tags:        t.Tags,
+	}
+	if t.Due != nil {
+		tw.Due = &Time{*t.Due}
+	}
+
+	switch t.Priority {
+	case task.PriorityHigh:
+		tw.Priority = "H"
+	case task.PriorityMedium:
+		tw.Priority = "M"
+	case task.PriorityLow:
+		tw.Priority = "L"
+	}
+
+	switch t.Status {
+	case task.StatusCompleted, task.StatusArchived:
+		tw.Status = "completed"
+	case task.StatusDeleted:
+		tw.Status = "deleted"
+	case task.StatusPending:
+		tw.Status = "waiting"
+	default:
+		tw.Status = "pending"
+	}
+	if tw.Status == "completed" || tw.Status == "deleted" {
+		end := t.UpdatedAt
+		if t.CompletedAt != nil {
+			end = *t.CompletedAt
+		}
+		tw.End = &Time{end}
+	}
+	if t.Status == task.StatusInProgress {
+		tw.Start = &Time{startedAt(t)}
+	}
+
+	for _, dep := range t.DependsOn {
+		if id := uuidOf(dep); id != "" {
+			tw.Depends = append(tw.Depends, id)
+		}
+	}
+	for _, a := range t.Annotations {
+		tw.Annotations = append(tw.Annotations, Annotation{Entry: Time{a.Entry}, Description: a.Description})
+	}
+	return tw
+}
+
+// startedAt returns when the task last moved to in_progress
+func startedAt(t *task.Task) time.Time {
+	for i := len(t.Transitions) - 1; i >= 0; i-- {
+		if t.Transitions[i].To == task.StatusInProgress {
+			return t.Transitions[i].At
+		}
+	}
+	return t.UpdatedAt
+}
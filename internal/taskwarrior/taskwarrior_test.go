@@ -0,0 +1,107 @@
+package taskwarrior
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// roundTrip imports a 'task export' document and exports the tasks again,
+// resolving dependencies through the UUIDs the way the import command does
+func roundTrip(t *testing.T, data []byte) []byte {
+	t.Helper()
+	exported, err := Read(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	var tasks []*task.Task
+	idOf := make(map[string]int)
+	for i, tw := range exported {
+		imported, err := tw.ToTask()
+		if err != nil {
+			t.Fatalf("ToTask(%s): %v", tw.UUID, err)
+		}
+		imported.ID = i + 1
+		idOf[tw.UUID] = imported.ID
+		tasks = append(tasks, imported)
+	}
+	for i, tw := range exported {
+		for _, uuid := range tw.Depends {
+			tasks[i].DependsOn = append(tasks[i].DependsOn, idOf[uuid])
+		}
+	}
+
+	index := task.IndexTasks(tasks)
+	uuidOf := func(id int) string {
+		if t, ok := index[id]; ok {
+			return t.SyncUUID()
+		}
+		return ""
+	}
+	var out []Task
+	for _, imported := range tasks {
+		out = append(out, FromTask(imported, uuidOf))
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, out); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestImportExportGolden(t *testing.T) {
+	input, err := os.ReadFile(filepath.Join("testdata", "import.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "export.json")
+
+	got := roundTrip(t, input)
+	if *update {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Export(Import(import.json)) differs from export.json; run with -update to see the change\ngot:\n%s", got)
+	}
+
+	// What taskman exports has to import back unchanged
+	if again := roundTrip(t, want); !bytes.Equal(again, want) {
+		t.Errorf("Export(Import(export.json)) differs from export.json\ngot:\n%s", again)
+	}
+}
+
+func TestReadLines(t *testing.T) {
+	// rc.json.array=off writes one task per line, with trailing commas in
+	// some versions
+	data := []byte(`{"uuid":"a","description":"one","entry":"20260301T090000Z","status":"pending"},
+{"uuid":"b","description":"two","entry":"20260301T090000Z","status":"pending"}
+`)
+	tasks, err := Read(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(tasks) != 2 || tasks[1].Description != "two" {
+		t.Errorf("Read = %+v", tasks)
+	}
+}
+
+func TestToTaskRejectsRecurringTemplates(t *testing.T) {
+	tw := Task{UUID: "a", Description: "Pay rent", Status: "recurring"}
+	if _, err := tw.ToTask(); err == nil {
+		t.Error("ToTask accepted a recurring template")
+	}
+}
@@ -0,0 +1,232 @@
+// Package todotxt reads and writes tasks in the todo.txt format
+// (https://github.com/todotxt/todo.txt):
+//
+//	(A) 2026-03-01 Call the bank +finance @phone due:2026-03-05
+//	x 2026-03-02 2026-03-01 Renew passport +admin pri:B
+//
+// Fields map onto tasks as follows:
+//
+//	x, completion date     status completed and CompletedAt
+//	(A), (B), (C)          priority high, medium, low; (D) to (Z) are low and
+//	                       tasks without a priority are medium
+//	pri:A                  priority of completed tasks, as written by todo.sh
+//	creation date          CreatedAt
+//	+project               Project; with several, the last one is used and the
+//	                       others stay in the description
+//	@context               a tag
+//	due:2026-03-05         Due, at the end of the day
+//
+// Other key:value pairs are kept in the description. Description words that
+// would read as one of the fields above, and words starting with a backslash,
+// are written with a backslash in front, which ParseLine removes again. Dates
+// carry no time of day, so times are lost on the way to todo.txt, and runs of
+// whitespace in descriptions become single spaces.
+package todotxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/vkhangstack/taskman/internal/dates"
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+const dateLayout = "2006-01-02"
+
+// Entry is a task read from a todo.txt file with the line it came from. Err
+// is set instead of Task for a line that could not be read.
+type Entry struct {
+	Line int
+	Task *task.Task
+	Err  error
+}
+
+// Parse reads a todo.txt file. Blank lines are skipped; dates are in loc. A
+// malformed line gives an Entry with Err set rather than failing the file.
+func Parse(r io.Reader, loc *time.Location) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		t, err := ParseLine(line, loc)
+		entries = append(entries, Entry{Line: n, Task: t, Err: err})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read todo.txt: %w", err)
+	}
+	return entries, nil
+}
+
+// ParseLine converts one todo.txt line into a task
+func ParseLine(line string, loc *time.Location) (*task.Task, error) {
+	t := &task.Task{Status: task.StatusTodo, Priority: task.PriorityMedium, Tags: []string{}}
+	words := strings.Fields(line)
+
+	if len(words) > 0 && words[0] == "x" {
+		t.Status = task.StatusCompleted
+		words = words[1:]
+		if d, ok := parseDate(words, loc); ok {
+			t.CompletedAt = &d
+			words = words[1:]
+		}
+	}
+	if len(words) > 0 && isPriority(words[0]) {
+		t.Priority = priorityOf(words[0][1])
+		words = words[1:]
+	}
+	if d, ok := parseDate(words, loc); ok {
+		t.CreatedAt = d
+		t.UpdatedAt = d
+		words = words[1:]
+	}
+	if t.CompletedAt != nil && t.UpdatedAt.Before(*t.CompletedAt) {
+		t.UpdatedAt = *t.CompletedAt
+	}
+
+	var desc, projects []string
+	for _, w := range words {
+		switch {
+		case len(w) > 1 && w[0] == '\\':
+			desc = append(desc, w[1:])
+		case len(w) > 1 && w[0] == '+' && task.ValidateProject(w[1:]) == nil:
+			projects = append(projects, w)
+		case len(w) > 1 && w[0] == '@':
+			if !t.HasTag(w[1:]) {
+				t.Tags = append(t.Tags, w[1:])
+			}
+		case strings.HasPrefix(w, "due:"):
+			d, err := time.ParseInLocation(dateLayout, w[len("due:"):], loc)
+			if err != nil {
+				return nil, fmt.Errorf("invalid due date %q", w)
+			}
+			d = dates.EndOfDay(d)
+			t.Due = &d
+		case strings.HasPrefix(w, "pri:") && len(w) == 5 && w[4] >= 'A' && w[4] <= 'Z':
+			t.Priority = priorityOf(w[4])
+		default:
+			desc = append(desc, w)
+		}
+	}
+
+	if len(projects) > 0 {
+		t.Project = projects[len(projects)-1][1:]
+		desc = append(desc, projects[:len(projects)-1]...)
+	}
+	t.Description = strings.Join(desc, " ")
+	if t.Description == "" {
+		return nil, fmt.Errorf("task has no description")
+	}
+	return t, nil
+}
+
+// Format converts a task into a todo.txt line
+func Format(t *task.Task) string {
+	var words []string
+	completed := t.IsCompleted()
+	if completed {
+		words = append(words, "x")
+		if t.CompletedAt != nil {
+			words = append(words, t.CompletedAt.Format(dateLayout))
+		}
+	}
+
+	letter := priorityLetter(t.Priority)
+	if letter != "" && !completed {
+		words = append(words, "("+letter+")")
+	}
+	if !t.CreatedAt.IsZero() && (!completed || t.CompletedAt != nil) {
+		words = append(words, t.CreatedAt.Format(dateLayout))
+	}
+
+	for i, w := range strings.Fields(t.Description) {
+		if needsEscape(w, i == 0) {
+			w = `\` + w
+		}
+		words = append(words, w)
+	}
+	if t.Project != "" {
+		words = append(words, "+"+t.Project)
+	}
+	for _, tag := range t.Tags {
+		words = append(words, "@"+tag)
+	}
+	if t.Due != nil {
+		words = append(words, "due:"+t.Due.Format(dateLayout))
+	}
+	if letter != "" && completed {
+		words = append(words, "pri:"+letter)
+	}
+	return strings.Join(words, " ")
+}
+
+// Write writes the tasks as todo.txt lines
+func Write(w io.Writer, tasks []*task.Task) error {
+	bw := bufio.NewWriter(w)
+	for _, t := range tasks {
+		if _, err := fmt.Fprintln(bw, Format(t)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// needsEscape reports whether ParseLine would not read a description word back
+// as part of the description. The first word could also be read as the done
+// marker, a priority or a date.
+func needsEscape(w string, first bool) bool {
+	switch {
+	case len(w) > 1 && w[0] == '\\',
+		len(w) > 1 && w[0] == '+' && task.ValidateProject(w[1:]) == nil,
+		len(w) > 1 && w[0] == '@',
+		strings.HasPrefix(w, "due:"),
+		strings.HasPrefix(w, "pri:") && len(w) == 5 && w[4] >= 'A' && w[4] <= 'Z':
+		return true
+	}
+	if !first {
+		return false
+	}
+	_, isDate := parseDate([]string{w}, time.UTC)
+	return w == "x" || isPriority(w) || isDate
+}
+
+func parseDate(words []string, loc *time.Location) (time.Time, bool) {
+	if len(words) == 0 {
+		return time.Time{}, false
+	}
+	d, err := time.ParseInLocation(dateLayout, words[0], loc)
+	return d, err == nil
+}
+
+func isPriority(w string) bool {
+	return len(w) == 3 && w[0] == '(' && w[2] == ')' && w[1] >= 'A' && w[1] <= 'Z'
+}
+
+func priorityOf(letter byte) string {
+	switch letter {
+	case 'A':
+		return task.PriorityHigh
+	case 'B':
+		return task.PriorityMedium
+	default:
+		return task.PriorityLow
+	}
+}
+
+func priorityLetter(priority string) string {
+	switch priority {
+	case task.PriorityHigh:
+		return "A"
+	case task.PriorityMedium:
+		return "B"
+	case task.PriorityLow:
+		return "C"
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package todotxt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// date returns midnight of the day in UTC, the precision todo.txt keeps
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRoundTrip(t *testing.T) {
+	created := date(2026, 3, 1)
+	done := date(2026, 3, 2)
+	due := time.Date(2026, 3, 5, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		task task.Task
+		line string
+	}{
+		{"plain", task.Task{Description: "Read a book", Priority: task.PriorityMedium}, "(B) Read a book"},
+		{"fields", task.Task{Description: "Call the bank", Priority: task.PriorityHigh, Project: "home.finance", Tags: []string{"phone", "errand"}, Due: &due, CreatedAt: created},
+			"(A) 2026-03-01 Call the bank +home.finance @phone @errand due:2026-03-05"},
+		{"completed", task.Task{Description: "Renew passport", Status: task.StatusCompleted, Priority: task.PriorityLow, CreatedAt: created, CompletedAt: &done},
+			"x 2026-03-02 2026-03-01 Renew passport pri:C"},
+		{"project word", task.Task{Description: "Add +1 to the counter", Priority: task.PriorityLow}, `(C) Add \+1 to the counter`},
+		{"context word", task.Task{Description: "Meet @ noon, then @home", Priority: task.PriorityMedium}, `(B) Meet @ noon, then \@home`},
+		{"due word", task.Task{Description: "Check due:tomorrow and pri:A notes", Priority: task.PriorityMedium}, `(B) Check \due:tomorrow and \pri:A notes`},
+		{"backslash word", task.Task{Description: `Fix C:\temp and \+x`, Priority: task.PriorityMedium}, `(B) Fix C:\temp and \\+x`},
+		{"leading x", task.Task{Description: "x marks the spot", Status: task.StatusCompleted, Priority: task.PriorityMedium}, `x \x marks the spot pri:B`},
+		{"leading priority", task.Task{Description: "(A) is the best grade", Status: task.StatusCompleted, Priority: task.PriorityMedium, CompletedAt: &done},
+			`x 2026-03-02 \(A) is the best grade pri:B`},
+		{"leading date", task.Task{Description: "2026-04-01 deadline", Status: task.StatusCompleted, Priority: task.PriorityLow, CompletedAt: &done},
+			`x 2026-03-02 \2026-04-01 deadline pri:C`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.task
+			if in.Status == "" {
+				in.Status = task.StatusTodo
+			}
+			if in.Tags == nil {
+				in.Tags = []string{}
+			}
+
+			line := Format(&in)
+			if line != tt.line {
+				t.Errorf("Format = %q, want %q", line, tt.line)
+			}
+
+			out, err := ParseLine(line, time.UTC)
+			if err != nil {
+				t.Fatalf("ParseLine(%q): %v", line, err)
+			}
+			// Dates come back at the day's precision, with the last change at
+			// the completion
+			want := in
+			if !want.CreatedAt.IsZero() {
+				want.UpdatedAt = want.CreatedAt
+			}
+			if want.CompletedAt != nil && want.UpdatedAt.Before(*want.CompletedAt) {
+				want.UpdatedAt = *want.CompletedAt
+			}
+			if !reflect.DeepEqual(*out, want) {
+				t.Errorf("ParseLine(%q) = %+v, want %+v", line, *out, want)
+			}
+		})
+	}
+}
+
+func TestFormatCollapsesWhitespace(t *testing.T) {
+	in := &task.Task{Description: "two\nlines  and\ttabs", Status: task.StatusTodo, Priority: task.PriorityMedium}
+	if got := Format(in); got != "(B) two lines and tabs" {
+		t.Errorf("Format = %q", got)
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		project string
+		desc    string
+		prio    string
+	}{
+		// With several projects the last one wins and the others stay in the description
+		{"Plan +trip +home", "home", "Plan +trip", task.PriorityMedium},
+		// Words that are not valid projects stay in the description
+		{"Learn C++ and +a:b", "", "Learn C++ and +a:b", task.PriorityMedium},
+		{"(D) Someday", "", "Someday", task.PriorityLow},
+		{"Tidy up key:value", "", "Tidy up key:value", task.PriorityMedium},
+		{`Lone \ backslash`, "", `Lone \ backslash`, task.PriorityMedium},
+	}
+	for _, tt := range tests {
+		got, err := ParseLine(tt.line, time.UTC)
+		if err != nil {
+			t.Errorf("ParseLine(%q): %v", tt.line, err)
+			continue
+		}
+		if got.Project != tt.project || got.Description != tt.desc || got.Priority != tt.prio {
+			t.Errorf("ParseLine(%q) = project %q, description %q, priority %s", tt.line, got.Project, got.Description, got.Priority)
+		}
+	}
+}
+
+func TestParseSkipsBadLines(t *testing.T) {
+	input := "(A) First\n\nSecond due:someday\n+project @context\nThird due:2026-03-05\n"
+	entries, err := Parse(strings.NewReader(input), time.UTC)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	var lines []int
+	var bad []string
+	for _, e := range entries {
+		if e.Err != nil {
+			bad = append(bad, e.Err.Error())
+			continue
+		}
+		lines = append(lines, e.Line)
+	}
+	if !reflect.DeepEqual(lines, []int{1, 5}) {
+		t.Errorf("read tasks from lines %v, want [1 5]", lines)
+	}
+	if want := []string{`invalid due date "due:someday"`, "task has no description"}; !reflect.DeepEqual(bad, want) {
+		t.Errorf("line errors = %q, want %q", bad, want)
+	}
+}
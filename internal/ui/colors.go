@@ -3,7 +3,10 @@ package ui
 import (
 	"fmt"
 	"github.com/vkhangstack/taskman/internal/task"
+	"io"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 )
@@ -30,24 +33,32 @@ var (
 	YellowBg = color.New(color.BgYellow, color.FgBlack)
 )
 
+// Messages is where the Print functions write. Structured output modes point it
+// at stderr so that stdout only carries data.
+var Messages io.Writer = os.Stdout
+
+// Out is where tables, summaries and task details are written. Commands that
+// page their output point it at a buffer.
+var Out io.Writer = os.Stdout
+
 // PrintSuccess prints a success message in green
 func PrintSuccess(message string) {
-	fmt.Printf("%s %s\n", GreenBold.Sprint("✓"), GreenText.Sprint(message))
+	fmt.Fprintf(Messages, "%s %s\n", GreenBold.Sprint("✓"), GreenText.Sprint(message))
 }
 
 // PrintError prints an error message in red
 func PrintError(message string) {
-	fmt.Printf("%s %s\n", RedBold.Sprint("✗"), RedText.Sprint(message))
+	fmt.Fprintf(Messages, "%s %s\n", RedBold.Sprint("✗"), RedText.Sprint(message))
 }
 
 // PrintWarning prints a warning message in yellow
 func PrintWarning(message string) {
-	fmt.Printf("%s %s\n", YellowBold.Sprint("⚠"), YellowText.Sprint(message))
+	fmt.Fprintf(Messages, "%s %s\n", YellowBold.Sprint("⚠"), YellowText.Sprint(message))
 }
 
 // PrintInfo prints an info message in blue
 func PrintInfo(message string) {
-	fmt.Printf("%s %s\n", BlueBold.Sprint("ℹ"), BlueText.Sprint(message))
+	fmt.Fprintf(Messages, "%s %s\n", BlueBold.Sprint("ℹ"), BlueText.Sprint(message))
 }
 
 // FormatPriority returns a colored priority indicator
@@ -108,3 +119,26 @@ func FormatDescription(description, status string) string {
 	}
 	return description
 }
+
+// FormatDue returns the task's due date, red when overdue and yellow when due today
+func FormatDue(t *task.Task) string {
+	if t.Due == nil {
+		return ""
+	}
+
+	layout := "02/01/2006 15:04"
+	if h, m, sec := t.Due.Clock(); h == 23 && m == 59 && sec == 59 {
+		layout = "02/01/2006"
+	}
+	due := t.Due.Format(layout)
+
+	now := time.Now()
+	switch {
+	case t.IsOverdue(now):
+		return RedBold.Sprint(due)
+	case t.IsDueToday(now):
+		return YellowBold.Sprint(due)
+	default:
+		return due
+	}
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// TableOptions control the task table
+type TableOptions struct {
+	Columns []string        // column names, DefaultColumns when empty
+	Urgency map[int]float64 // urgency scores by task ID
+	Marked  map[int]bool    // IDs of tasks whose rows are marked, e.g. as changed
+}
+
+// DefaultColumns are the columns of the task table unless --columns is given
+var DefaultColumns = []string{"id", "status", "priority", "urgency", "project", "desc", "tags", "due", "created"}
+
+// column is a column of the task table. The description column is built by
+// displayTasks, which fits it to the terminal.
+type column struct {
+	header string
+	value  func(t *task.Task, opts TableOptions) string
+}
+
+var columns = map[string]column{
+	"id":       {"ID", func(t *task.Task, _ TableOptions) string { return FormatID(t.ID) }},
+	"status":   {"Status", func(t *task.Task, _ TableOptions) string { return FormatStatus(t.Status) }},
+	"priority": {"Priority", func(t *task.Task, _ TableOptions) string { return FormatPriority(t.Priority) }},
+	"urgency": {"Urgency", func(t *task.Task, opts TableOptions) string {
+		return FormatUrgency(t, opts.Urgency[t.ID])
+	}},
+	"project": {"Project", func(t *task.Task, _ TableOptions) string { return t.Project }},
+	"desc":    {"Description", nil},
+	"tags":    {"Tags", func(t *task.Task, _ TableOptions) string { return FormatTags(t.Tags) }},
+	"due":     {"Due", func(t *task.Task, _ TableOptions) string { return FormatDue(t) }},
+	"created": {"Created", func(t *task.Task, _ TableOptions) string { return t.CreatedAt.Format("02/01/2006 15:04") }},
+	"updated": {"Updated", func(t *task.Task, _ TableOptions) string { return t.UpdatedAt.Format("02/01/2006 15:04") }},
+	"completed": {"Completed", func(t *task.Task, _ TableOptions) string {
+		if t.CompletedAt == nil {
+			return ""
+		}
+		return t.CompletedAt.Format("02/01/2006 15:04")
+	}},
+	"recur": {"Recur", func(t *task.Task, _ TableOptions) string { return t.Recur }},
+	"parent": {"Parent", func(t *task.Task, _ TableOptions) string {
+		if t.ParentID == 0 {
+			return ""
+		}
+		return FormatID(t.ParentID)
+	}},
+	"depends": {"Depends", func(t *task.Task, _ TableOptions) string {
+		var ids []string
+		for _, id := range t.DependsOn {
+			ids = append(ids, FormatID(id))
+		}
+		return strings.Join(ids, " ")
+	}},
+	"spent": {"Spent", func(t *task.Task, _ TableOptions) string {
+		if len(t.TimeLog) == 0 {
+			return ""
+		}
+		return FormatSpan(t.TimeSpent(time.Now()))
+	}},
+}
+
+// columnAliases are the other accepted names of columns
+var columnAliases = map[string]string{
+	"description": "desc",
+	"pri":         "priority",
+	"proj":        "project",
+}
+
+// ParseColumns validates a comma-separated list of column names
+func ParseColumns(spec string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if alias, ok := columnAliases[name]; ok {
+			name = alias
+		}
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("invalid column: %s. Valid columns are: id, status, priority, urgency, project, desc, tags, due, created, updated, completed, recur, parent, depends, spent", name)
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no columns given")
+	}
+	return names, nil
+}
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// displayWidth returns the number of runes of s without color escapes
+func displayWidth(s string) int {
+	return utf8.RuneCountInString(ansiEscape.ReplaceAllString(s, ""))
+}
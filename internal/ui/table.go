@@ -4,13 +4,167 @@ import (
 	"fmt"
 	"github.com/olekukonko/tablewriter"
 	"github.com/vkhangstack/taskman/internal/task"
-	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // DisplayTasksTable displays tasks in a formatted table
-func DisplayTasksTable(tasks []*task.Task) {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"ID", "Status", "Priority", "Description", "Tags", "Created"})
+func DisplayTasksTable(tasks []*task.Task, opts TableOptions) {
+	displayTasks(tasks, opts, func(t *task.Task) (string, string) {
+		return "", ""
+	})
+}
+
+// DisplayTasksTree displays tasks in the task table with subtasks indented
+// below their parents. Parents show how many of their subtasks in all are
+// completed.
+func DisplayTasksTree(tasks []*task.Task, all []*task.Task, opts TableOptions) {
+	children := task.ChildrenIndex(all)
+	depth := make(map[int]int)
+	var ordered []*task.Task
+	for _, e := range task.FlattenTree(tasks) {
+		ordered = append(ordered, e.Task)
+		depth[e.Task.ID] = e.Depth
+	}
+
+	displayTasks(ordered, opts, func(t *task.Task) (prefix, suffix string) {
+		if d := depth[t.ID]; d > 0 {
+			prefix = strings.Repeat("  ", d-1) + "└ "
+		}
+		if p := task.RollUp(children, t.ID); p.Total > 0 {
+			suffix = FormatProgress(p)
+		}
+		return prefix, suffix
+	})
+}
+
+// FormatTimer returns the running time of the task's active timer, e.g. "⏱ 1h 5m"
+func FormatTimer(t *task.Task) string {
+	e := t.ActiveTimer()
+	if e == nil {
+		return ""
+	}
+	return YellowBold.Sprintf("⏱ %s", FormatSpan(e.Duration(time.Now())))
+}
+
+// FormatUrgency returns the urgency score of an open task with one decimal
+func FormatUrgency(t *task.Task, score float64) string {
+	if !t.IsOpen() {
+		return ""
+	}
+	s := fmt.Sprintf("%.1f", score)
+	switch {
+	case score >= 10:
+		return RedBold.Sprint(s)
+	case score >= 5:
+		return YellowText.Sprint(s)
+	}
+	return s
+}
+
+// FormatProgress returns the roll-up progress of a parent task, e.g. "[2/3 66%]"
+func FormatProgress(p task.Progress) string {
+	s := fmt.Sprintf("[%d/%d %d%%]", p.Done, p.Total, p.Percent())
+	if p.Done == p.Total {
+		return GreenText.Sprint(s)
+	}
+	return CyanText.Sprint(s)
+}
+
+// displayTasks renders the task table. decorate returns the text around the
+// description of a task; when stdout is a terminal, descriptions are truncated
+// so that rows fit its width.
+func displayTasks(tasks []*task.Task, opts TableOptions, decorate func(t *task.Task) (prefix, suffix string)) {
+	names := opts.Columns
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+
+	var header []string
+	for _, name := range names {
+		header = append(header, columns[name].header)
+	}
+
+	rows := make([][]string, len(tasks))
+	descWidth := -1
+	for i, t := range tasks {
+		for _, name := range names {
+			if c := columns[name]; c.value != nil {
+				rows[i] = append(rows[i], c.value(t, opts))
+			} else {
+				rows[i] = append(rows[i], "")
+			}
+		}
+	}
+	if width, _, ok := TerminalSize(); ok {
+		// Stay clear of the last column so that the terminal does not wrap
+		descWidth = width - otherColumnsWidth(names, header, rows) - 1
+	}
+
+	for i, t := range tasks {
+		prefix, suffix := decorate(t)
+		if opts.Marked[t.ID] {
+			prefix = YellowBold.Sprint("» ") + prefix
+		}
+		if timer := FormatTimer(t); timer != "" {
+			suffix = strings.TrimSpace(suffix + " " + timer)
+		}
+		if suffix != "" {
+			suffix = " " + suffix
+		}
+
+		desc := t.Description
+		if descWidth >= 0 {
+			// Keep at least a few characters of the description on narrow terminals
+			desc = truncateString(desc, max(descWidth-displayWidth(prefix+suffix), 10))
+		}
+		for j, name := range names {
+			if name == "desc" {
+				rows[i][j] = prefix + FormatDescription(desc, t.Status) + suffix
+			}
+		}
+	}
+
+	table := tablewriter.NewWriter(Out)
+	table.SetHeader(header)
+
+	// Configure table appearance
+	table.SetBorder(false)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.AppendBulk(rows)
+
+	table.Render()
+}
+
+// otherColumnsWidth returns the width of the table without the description:
+// the widest header or cell of every other column plus the padding
+func otherColumnsWidth(names, header []string, rows [][]string) int {
+	width := 2
+	for j, name := range names {
+		width += 2
+		if name == "desc" {
+			continue
+		}
+		w := utf8.RuneCountInString(header[j])
+		for _, row := range rows {
+			w = max(w, displayWidth(row[j]))
+		}
+		width += w
+	}
+	return width
+}
+
+// DisplaySeriesTable displays recurring series in a formatted table
+func DisplaySeriesTable(series []*task.Series) {
+	table := tablewriter.NewWriter(Out)
+	table.SetHeader([]string{"Series", "Rule", "Description", "Next", "Due", "Done", "State"})
 
 	// Configure table appearance
 	table.SetBorder(false)
@@ -21,17 +175,71 @@ func DisplayTasksTable(tasks []*task.Task) {
 	table.SetAlignment(tablewriter.ALIGN_LEFT)
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
 
-	// Add rows
-	for _, t := range tasks {
-		row := []string{
-			FormatID(t.ID),
-			FormatStatus(t.Status),
-			FormatPriority(t.Priority),
-			FormatDescription(t.Description, t.Status),
-			FormatTags(t.Tags),
-			t.CreatedAt.Format("02/01/2006 15:04"),
+	for _, s := range series {
+		latest := s.Tasks[len(s.Tasks)-1]
+		next, due := "", ""
+		if current := s.Current(); current != nil {
+			latest = current
+			next = FormatID(current.ID)
+			due = FormatDue(current)
 		}
-		table.Append(row)
+
+		state := GreenText.Sprint("active")
+		if !s.IsActive() {
+			state = WhiteText.Sprint("stopped")
+		}
+
+		table.Append([]string{
+			FormatID(s.ID),
+			s.Rule,
+			latest.Description,
+			next,
+			due,
+			fmt.Sprintf("%d", s.Completed()),
+			state,
+		})
+	}
+
+	table.Render()
+}
+
+// DisplayHistoryTable displays journal entries in a formatted table
+func DisplayHistoryTable(entries []*task.JournalEntry) {
+	table := tablewriter.NewWriter(Out)
+	table.SetHeader([]string{"#", "Time", "Operation", "Task", "Changes"})
+
+	// Configure table appearance
+	table.SetBorder(false)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+
+	for _, e := range entries {
+		op := e.Op
+		switch {
+		case e.Op == task.OpUndo || e.Op == task.OpRedo:
+			op = CyanText.Sprintf("%s %d", e.Op, e.Ref)
+		case e.Undone:
+			op = WhiteText.Sprintf("%s (undone)", e.Op)
+		}
+
+		var ids, summaries []string
+		for _, c := range e.Changes {
+			ids = append(ids, FormatID(c.TaskID))
+			summaries = append(summaries, c.Summary())
+		}
+
+		table.Append([]string{
+			fmt.Sprintf("%d", e.Seq),
+			e.Time.Format("02/01/2006 15:04"),
+			op,
+			strings.Join(ids, "\n"),
+			strings.Join(summaries, "\n"),
+		})
 	}
 
 	table.Render()
@@ -39,76 +247,142 @@ func DisplayTasksTable(tasks []*task.Task) {
 
 // DisplayTaskDetails displays detailed information about a single task
 func DisplayTaskDetails(t *task.Task) {
-	fmt.Printf("\n")
-	fmt.Printf("Task %s\n", FormatID(t.ID))
-	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Fprintf(Out, "\n")
+	fmt.Fprintf(Out, "Task %s\n", FormatID(t.ID))
+	fmt.Fprintf(Out, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 
-	fmt.Printf("Description: %s\n", FormatDescription(t.Description, t.Status))
-	fmt.Printf("Status:      %s\n", FormatStatus(t.Status))
-	fmt.Printf("Priority:    %s\n", FormatPriority(t.Priority))
+	fmt.Fprintf(Out, "Description: %s\n", FormatDescription(t.Description, t.Status))
+	fmt.Fprintf(Out, "Status:      %s\n", FormatStatus(t.Status))
+	fmt.Fprintf(Out, "Priority:    %s\n", FormatPriority(t.Priority))
 
 	if len(t.Tags) > 0 {
-		fmt.Printf("Tags:        %s\n", FormatTags(t.Tags))
+		fmt.Fprintf(Out, "Tags:        %s\n", FormatTags(t.Tags))
+	}
+
+	if t.Due != nil {
+		fmt.Fprintf(Out, "Due:         %s\n", FormatDue(t))
+	}
+
+	if t.IsRecurring() || t.RecurParent != 0 {
+		rule := t.Recur
+		if rule == "" {
+			rule = "stopped"
+		}
+		fmt.Fprintf(Out, "Recurs:      %s (series %s)\n", rule, FormatID(t.SeriesID()))
 	}
 
-	fmt.Printf("Created:     %s\n", t.CreatedAt.Format("02/01/2006 15:04"))
-	fmt.Printf("Updated:     %s\n", t.UpdatedAt.Format("02/01/2006 15:04"))
+	if t.Project != "" {
+		fmt.Fprintf(Out, "Project:     %s\n", t.Project)
+	}
+
+	if len(t.TimeLog) > 0 {
+		fmt.Fprintf(Out, "Time spent:  %s %s\n", FormatSpan(t.TimeSpent(time.Now())), FormatTimer(t))
+	}
+
+	if t.ParentID != 0 {
+		fmt.Fprintf(Out, "Parent:      %s\n", FormatID(t.ParentID))
+	}
+
+	if len(t.DependsOn) > 0 {
+		var deps []string
+		for _, id := range t.DependsOn {
+			deps = append(deps, FormatID(id))
+		}
+		fmt.Fprintf(Out, "Depends on:  %s\n", strings.Join(deps, " "))
+	}
+
+	fmt.Fprintf(Out, "Created:     %s\n", t.CreatedAt.Format("02/01/2006 15:04"))
+	fmt.Fprintf(Out, "Updated:     %s\n", t.UpdatedAt.Format("02/01/2006 15:04"))
 
 	if t.CompletedAt != nil {
-		fmt.Printf("Completed:   %s\n", t.CompletedAt.Format("02/01/2006 15:04"))
+		fmt.Fprintf(Out, "Completed:   %s %s\n", t.CompletedAt.Format("02/01/2006 15:04"), FormatDuration(t))
+	} else {
+		fmt.Fprintf(Out, "Age:         %s\n", FormatSpan(time.Since(t.CreatedAt)))
 	}
 
-	fmt.Printf("\n")
+	fmt.Fprintf(Out, "\n")
+	fmt.Fprintf(Out, "Timeline\n")
+	now := time.Now()
+	for _, p := range t.Timeline() {
+		end, span := now, ""
+		if p.End != nil {
+			end = *p.End
+		}
+		if p.End != nil || t.IsOpen() {
+			span = fmt.Sprintf(" (%s)", FormatSpan(end.Sub(p.Start)))
+		}
+		fmt.Fprintf(Out, "  %s  %s%s\n", p.Start.Format("02/01/2006 15:04"), FormatStatus(p.Status), span)
+	}
+
+	if inProgress := t.TimeInStatus(task.StatusInProgress, now); inProgress > 0 {
+		fmt.Fprintf(Out, "\n")
+		fmt.Fprintf(Out, "In progress: %s\n", FormatSpan(inProgress))
+	}
+
+	if len(t.Annotations) > 0 {
+		fmt.Fprintf(Out, "\n")
+		fmt.Fprintf(Out, "Annotations\n")
+		for _, a := range t.Annotations {
+			fmt.Fprintf(Out, "  %s  %s\n", a.Entry.Local().Format("02/01/2006 15:04"), a.Description)
+		}
+	}
+
+	fmt.Fprintf(Out, "\n")
 }
 
 // DisplayTasksSummary displays a summary of tasks by status and priority
 func DisplayTasksSummary(tasks []*task.Task) {
-	pending := 0
-	completed := 0
-	high := 0
-	medium := 0
-	low := 0
-
-	for _, t := range tasks {
-		switch t.Status {
-		case task.StatusPending:
-		case task.StatusTodo:
-		case task.StatusInProgress:
-			pending++
-		case task.StatusCompleted:
-			completed++
-		}
-
-		switch t.Priority {
-		case task.PriorityHigh:
-			high++
-		case task.PriorityMedium:
-			medium++
-		case task.PriorityLow:
-			low++
-		}
-	}
-
-	fmt.Printf("\n")
-	fmt.Printf("Task Summary\n")
-	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-	fmt.Printf("Total tasks:     %d\n", len(tasks))
-	fmt.Printf("Pending:         %s (%d)\n", YellowText.Sprint("⏳"), pending)
-	fmt.Printf("Completed:       %s (%d)\n", GreenText.Sprint("✓"), completed)
-	fmt.Printf("\n")
-	fmt.Printf("By Priority:\n")
-	fmt.Printf("High:            %s (%d)\n", RedText.Sprint("●"), high)
-	fmt.Printf("Medium:          %s (%d)\n", YellowText.Sprint("●"), medium)
-	fmt.Printf("Low:             %s (%d)\n", GreenText.Sprint("●"), low)
-	fmt.Printf("\n")
+	DisplayTasksSummaryTitled("Task Summary", tasks)
+}
+
+// DisplayTasksSummaryTitled displays the task summary under the given title
+func DisplayTasksSummaryTitled(title string, tasks []*task.Task) {
+	s := task.Summarize(tasks)
+
+	fmt.Fprintf(Out, "\n")
+	fmt.Fprintf(Out, "%s\n", title)
+	fmt.Fprintf(Out, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Fprintf(Out, "Total tasks:     %d\n", s.Total)
+	fmt.Fprintf(Out, "Pending:         %s (%d)\n", YellowText.Sprint("⏳"), s.Pending)
+	fmt.Fprintf(Out, "Completed:       %s (%d)\n", GreenText.Sprint("✓"), s.Completed)
+	if progress, ok := s.Progress(); ok {
+		fmt.Fprintf(Out, "Progress:        %d%%\n", progress)
+	}
+	fmt.Fprintf(Out, "\n")
+	fmt.Fprintf(Out, "By Priority:\n")
+	fmt.Fprintf(Out, "High:            %s (%d)\n", RedText.Sprint("●"), s.High)
+	fmt.Fprintf(Out, "Medium:          %s (%d)\n", YellowText.Sprint("●"), s.Medium)
+	fmt.Fprintf(Out, "Low:             %s (%d)\n", GreenText.Sprint("●"), s.Low)
+	fmt.Fprintf(Out, "\n")
 }
 
 // truncateString truncates a string to the specified length
 func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
-	return s[:maxLen-3] + "..."
+	if maxLen <= 3 {
+		return string(runes[:max(maxLen, 0)])
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
+
+// FormatSpan returns a compact human-readable duration such as "3d 4h" or "25m"
+func FormatSpan(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return "< 1m"
 }
 
 // FormatDuration returns a human-readable duration string
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// TemplateFuncs are the helpers available to list --format templates, in
+// addition to the text/template builtins
+var TemplateFuncs = template.FuncMap{
+	// Strings
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trunc": func(n int, s string) string { return truncateString(s, n) },
+	"pad":   func(n int, s string) string { return fmt.Sprintf("%-*s", n, s) },
+	"join":  func(sep string, items []string) string { return strings.Join(items, sep) },
+	"tags":  joinTags,
+	"default": func(def string, s string) string {
+		if s == "" {
+			return def
+		}
+		return s
+	},
+
+	// Times
+	"date":     formatTemplateDate,
+	"relative": relativeTime,
+
+	// Colors
+	"red":    colorFunc(RedText),
+	"green":  colorFunc(GreenText),
+	"yellow": colorFunc(YellowText),
+	"blue":   colorFunc(BlueText),
+	"cyan":   colorFunc(CyanText),
+	"bold":   colorFunc(color.New(color.Bold)),
+
+	// The helpers used by the tables
+	"formatID":          FormatID,
+	"formatStatus":      FormatStatus,
+	"formatPriority":    FormatPriority,
+	"formatTags":        FormatTags,
+	"formatDue":         FormatDue,
+	"formatDescription": FormatDescription,
+	"formatDuration":    FormatDuration,
+}
+
+// ParseTaskTemplate parses a list --format template. Template output is
+// followed by a newline for every task unless it already ends in one.
+func ParseTaskTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("format").Funcs(TemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderTasks executes the template once per task
+func RenderTasks(w io.Writer, tmpl *template.Template, tasks []*task.Task) error {
+	var buf strings.Builder
+	for _, t := range tasks {
+		buf.Reset()
+		if err := tmpl.Execute(&buf, t); err != nil {
+			return fmt.Errorf("failed to render task %d: %w", t.ID, err)
+		}
+		line := buf.String()
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func colorFunc(c *color.Color) func(s string) string {
+	return func(s string) string { return c.Sprint(s) }
+}
+
+// joinTags joins tags as "#work #urgent"
+func joinTags(tags []string) string {
+	var formatted []string
+	for _, tag := range tags {
+		formatted = append(formatted, "#"+tag)
+	}
+	return strings.Join(formatted, " ")
+}
+
+// templateTime accepts the time.Time and *time.Time fields of a task
+func templateTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, false
+		}
+		return *t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// formatTemplateDate formats a time with a Go layout, or returns "" for unset
+// times such as the due date of a task without one
+func formatTemplateDate(layout string, v any) string {
+	t, ok := templateTime(v)
+	if !ok {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// relativeTime describes a time relative to now, e.g. "3d 2h ago" or "in 5h 10m"
+func relativeTime(v any) string {
+	t, ok := templateTime(v)
+	if !ok {
+		return ""
+	}
+	d := time.Until(t)
+	if d < 0 {
+		return FormatSpan(-d) + " ago"
+	}
+	return "in " + FormatSpan(d)
+}
@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package ui
+
+// TerminalSize reports that stdout is not a terminal on platforms without a
+// way to query it
+func TerminalSize() (int, int, bool) {
+	return 0, 0, false
+}
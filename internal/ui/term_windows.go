@@ -0,0 +1,20 @@
+//go:build windows
+
+package ui
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// TerminalSize returns the width and height of the console on stdout, and
+// false when stdout is not a console
+func TerminalSize() (int, int, bool) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(os.Stdout.Fd()), &info); err != nil {
+		return 0, 0, false
+	}
+	w := info.Window
+	return int(w.Right-w.Left) + 1, int(w.Bottom-w.Top) + 1, true
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// TimesheetRow is the time spent on a task, tag or project on each day of a
+// timesheet
+type TimesheetRow struct {
+	Label string
+	Days  []time.Duration
+	Total time.Duration
+}
+
+// DisplayTimesheet displays the rows with one column per day, followed by a
+// total row
+func DisplayTimesheet(heading string, days []time.Time, rows []TimesheetRow, total TimesheetRow) {
+	table := tablewriter.NewWriter(Out)
+	header := []string{heading}
+	for _, d := range days {
+		header = append(header, d.Format("Mon 02/01"))
+	}
+	table.SetHeader(append(header, "Total"))
+
+	// Configure table appearance
+	table.SetBorder(false)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+
+	appendRow := func(r TimesheetRow, label string) {
+		row := []string{label}
+		for _, d := range r.Days {
+			row = append(row, FormatClock(d))
+		}
+		table.Append(append(row, CyanBold.Sprint(FormatClock(r.Total))))
+	}
+	for _, r := range rows {
+		appendRow(r, truncateString(r.Label, 40))
+	}
+	appendRow(total, CyanBold.Sprint("Total"))
+
+	table.Render()
+}
+
+// FormatClock returns a duration as hours and minutes, e.g. "1:30", or "-" for none
+func FormatClock(d time.Duration) string {
+	if d <= 0 {
+		return WhiteText.Sprint("-")
+	}
+	minutes := int(d.Round(time.Minute).Minutes())
+	return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/vkhangstack/taskman/internal/task"
+)
+
+// PrintTree prints the tasks as trees using box-drawing characters:
+//
+//	#1 Deploy TODO
+//	├── #2 Build DONE
+//	└── #3 Test TODO
+//
+// children returns the tasks shown below a task. A task already on the path
+// from its root is not expanded again, so cyclic data cannot loop forever.
+func PrintTree(w io.Writer, roots []*task.Task, children func(t *task.Task) []*task.Task, label func(t *task.Task) string) {
+	onPath := make(map[int]bool)
+
+	var walk func(t *task.Task, prefix string)
+	walk = func(t *task.Task, prefix string) {
+		onPath[t.ID] = true
+		defer delete(onPath, t.ID)
+
+		kids := children(t)
+		for i, c := range kids {
+			branch, indent := "├── ", "│   "
+			if i == len(kids)-1 {
+				branch, indent = "└── ", "    "
+			}
+			if onPath[c.ID] {
+				fmt.Fprintf(w, "%s%s%s (cycle)\n", prefix, branch, label(c))
+				continue
+			}
+			fmt.Fprintf(w, "%s%s%s\n", prefix, branch, label(c))
+			walk(c, prefix+indent)
+		}
+	}
+
+	for _, root := range roots {
+		fmt.Fprintln(w, label(root))
+		walk(root, "")
+	}
+}
+
+// TaskLabel returns the one-line label used for tasks in trees
+func TaskLabel(t *task.Task) string {
+	return fmt.Sprintf("%s %s %s", FormatID(t.ID), FormatDescription(t.Description, t.Status), FormatStatus(t.Status))
+}
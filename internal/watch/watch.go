@@ -0,0 +1,100 @@
+// Package watch reports changes to the task store file.
+//
+// Stores replace their file on every save, so the directory holding it is
+// watched rather than the file itself, and only events on the file, or on the
+// write-ahead log and journal of an SQLite database, count. A save writes
+// several times in a row; changes are reported once the writes have settled.
+package watch
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDelay is how long writes have to settle before a change is reported
+const DefaultDelay = 150 * time.Millisecond
+
+// Watcher reports changes to a file
+type Watcher struct {
+	// C receives a value after the file changed, and is closed by Close.
+	// Changes that happen before the previous one is received are merged
+	// into it.
+	C <-chan struct{}
+	// Errors receives the errors of the underlying watcher
+	Errors <-chan error
+
+	fsw   *fsnotify.Watcher
+	names map[string]bool
+	delay time.Duration
+	c     chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// New watches the file at path and reports its changes after delay without
+// further writes
+func New(path string, delay time.Duration) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+	if err := fsw.Add(filepath.Dir(path)); err != nil {
+		fsw.Close()
+		return nil, fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+
+	base := filepath.Base(path)
+	c := make(chan struct{}, 1)
+	w := &Watcher{
+		C:      c,
+		Errors: fsw.Errors,
+		fsw:    fsw,
+		names:  map[string]bool{base: true, base + "-wal": true, base + "-journal": true},
+		delay:  delay,
+		c:      c,
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.fsw.Close()
+	})
+	return err
+}
+
+// run debounces the events on the watched file
+func (w *Watcher) run() {
+	timer := time.NewTimer(w.delay)
+	timer.Stop()
+	defer timer.Stop()
+	defer close(w.c)
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case ev, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if w.names[filepath.Base(ev.Name)] && ev.Op != fsnotify.Chmod {
+				timer.Reset(w.delay)
+			}
+		case <-timer.C:
+			select {
+			case w.c <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
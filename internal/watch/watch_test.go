@@ -0,0 +1,111 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testDelay = 100 * time.Millisecond
+
+// newWatcher watches tasks.json in a new directory
+func newWatcher(t *testing.T) (*Watcher, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	if err := os.WriteFile(path, []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := New(path, testDelay)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+	return w, path
+}
+
+// signals counts the values on C until it stays quiet for a few delays
+func signals(t *testing.T, w *Watcher) int {
+	t.Helper()
+	n := 0
+	for {
+		select {
+		case _, ok := <-w.C:
+			if !ok {
+				t.Fatal("C closed")
+			}
+			n++
+		case err := <-w.Errors:
+			t.Fatalf("watch error: %v", err)
+		case <-time.After(3 * testDelay):
+			return n
+		}
+	}
+}
+
+func TestWritesAreDebounced(t *testing.T) {
+	w, path := newWatcher(t)
+
+	for i := 0; i < 5; i++ {
+		if err := os.WriteFile(path, []byte{'[', byte('0' + i), ']'}, 0644); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(testDelay / 5)
+	}
+	if n := signals(t, w); n != 1 {
+		t.Errorf("got %d signals for a burst of writes, want 1", n)
+	}
+
+	// A later save is reported again, also when it replaces the file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	if n := signals(t, w); n != 1 {
+		t.Errorf("got %d signals for a replaced file, want 1", n)
+	}
+}
+
+func TestOtherFilesAreIgnored(t *testing.T) {
+	w, path := newWatcher(t)
+
+	dir := filepath.Dir(path)
+	for _, name := range []string{"notes.txt", "tasks.json.bak", "tasks.journal.jsonl"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := signals(t, w); n != 0 {
+		t.Errorf("got %d signals for other files, want 0", n)
+	}
+
+	// The write-ahead log of an SQLite database counts
+	if err := os.WriteFile(path+"-wal", []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if n := signals(t, w); n != 1 {
+		t.Errorf("got %d signals for the write-ahead log, want 1", n)
+	}
+}
+
+func TestCloseClosesC(t *testing.T) {
+	w, _ := newWatcher(t)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	select {
+	case _, ok := <-w.C:
+		if ok {
+			t.Error("got a signal after Close")
+		}
+	case <-time.After(time.Second):
+		t.Error("C is still open after Close")
+	}
+	// Closing twice is fine
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}